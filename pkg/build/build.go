@@ -0,0 +1,160 @@
+package build
+
+import (
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+)
+
+// buildctl is the client of buildkit, it sends the local build context and
+// streams the progress of the build. The client library of buildkit can not be
+// used as its dependencies conflict with the ones of helm.
+const buildctl = "buildctl"
+
+const (
+	ProgressAuto  = "auto"
+	ProgressTty   = "tty"
+	ProgressPlain = "plain"
+)
+
+var ProgressModes = []string{ProgressAuto, ProgressTty, ProgressPlain}
+
+// Options configure a build with the dockerfile frontend of buildkit
+type Options struct {
+	// ContextPath is the local folder sent to buildkit as build context
+	ContextPath string
+	// Dockerfile defaults to the Dockerfile in the build context
+	Dockerfile string
+	Tags       []string
+	BuildArgs  map[string]string
+	Target     string
+	Platforms  []string
+	NoCache    bool
+	// Push pushes the image using the local docker credentials
+	Push bool
+	// OutputFile writes the image as docker archive, without it and without
+	// push the result is only kept in the cache of buildkit
+	OutputFile string
+	Progress   string
+}
+
+func (o *Options) dockerfile() string {
+	if o.Dockerfile == "" {
+		return filepath.Join(o.ContextPath, "Dockerfile")
+	}
+	return o.Dockerfile
+}
+
+// csvField joins the key and value of an output attribute, values containing
+// commas are quoted
+func csvField(key string, value string) string {
+	var builder strings.Builder
+
+	writer := csv.NewWriter(&builder)
+	writer.Write([]string{key + "=" + value})
+	writer.Flush()
+
+	return strings.TrimSuffix(builder.String(), "\n")
+}
+
+func (o *Options) output() string {
+	var fields []string
+
+	switch {
+	case o.OutputFile != "":
+		fields = append(fields, "type=docker", csvField("dest", o.OutputFile))
+	case o.Push || len(o.Tags) > 0:
+		fields = append(fields, "type=image")
+	default:
+		return ""
+	}
+
+	if len(o.Tags) > 0 {
+		fields = append(fields, csvField("name", strings.Join(o.Tags, ",")))
+	}
+
+	if o.Push {
+		fields = append(fields, "push=true")
+	}
+
+	return strings.Join(fields, ",")
+}
+
+func (o *Options) args(address string) []string {
+	args := []string{
+		"--addr", address,
+		"build",
+		"--frontend", "dockerfile.v0",
+		"--local", "context=" + o.ContextPath,
+		"--local", "dockerfile=" + filepath.Dir(o.dockerfile()),
+		"--opt", "filename=" + filepath.Base(o.dockerfile()),
+		"--progress", o.Progress,
+	}
+
+	if o.Target != "" {
+		args = append(args, "--opt", "target="+o.Target)
+	}
+
+	if len(o.Platforms) > 0 {
+		args = append(args, "--opt", "platform="+strings.Join(o.Platforms, ","))
+	}
+
+	for key, value := range o.BuildArgs {
+		args = append(args, "--opt", fmt.Sprintf("build-arg:%s=%s", key, value))
+	}
+
+	if o.NoCache {
+		args = append(args, "--no-cache")
+	}
+
+	if output := o.output(); output != "" {
+		args = append(args, "--output", output)
+	}
+
+	return args
+}
+
+// Validate checks the build context and the combination of the options
+func (o *Options) Validate() error {
+	for _, path := range []string{o.ContextPath, o.dockerfile()} {
+		if _, err := os.Stat(path); err != nil {
+			return err
+		}
+	}
+
+	if o.Push && len(o.Tags) == 0 {
+		return errors.New("pushing requires at least one tag")
+	}
+
+	if o.Push && o.OutputFile != "" {
+		return errors.New("an image can not be pushed and written to a file at the same time")
+	}
+
+	for _, mode := range ProgressModes {
+		if o.Progress == mode {
+			return nil
+		}
+	}
+
+	return fmt.Errorf("invalid progress mode %s, expected one of %s", o.Progress, strings.Join(ProgressModes, ", "))
+}
+
+// Build sends the build context to buildkit listening on address. The
+// progress is streamed to stderr.
+func Build(address string, options Options) error {
+	executable, err := exec.LookPath(buildctl)
+	if err != nil {
+		return fmt.Errorf("%s is required to build images, see https://github.com/moby/buildkit/releases: %w", buildctl, err)
+	}
+
+	command := exec.Command(executable, options.args(address)...)
+	command.Stdin = os.Stdin
+	command.Stdout = os.Stdout
+	command.Stderr = os.Stderr
+
+	return command.Run()
+}
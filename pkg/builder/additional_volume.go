@@ -0,0 +1,229 @@
+package builder
+
+import (
+	"fmt"
+	"path"
+	"strconv"
+	"strings"
+
+	"k8s.io/apimachinery/pkg/api/resource"
+	"k8s.io/apimachinery/pkg/util/validation"
+)
+
+const (
+	VolumeTypePvc       = "pvc"
+	VolumeTypeConfigMap = "configmap"
+	VolumeTypeSecret    = "secret"
+	VolumeTypeEmptyDir  = "emptydir"
+	VolumeTypeNfs       = "nfs"
+)
+
+// reservedMountPaths are mounted by the chart
+var reservedMountPaths = map[string]string{
+	"/home/workspace":           "the home volume",
+	"/opt/conda/envs/workspace": "the conda environment volume",
+	"/dev/shm":                  "the shared memory volume, use --shm-size instead",
+}
+
+// volumeSources is the number of fields before the mount path per volume type
+var volumeSources = map[string]int{
+	VolumeTypePvc:       1,
+	VolumeTypeConfigMap: 1,
+	VolumeTypeSecret:    1,
+	VolumeTypeEmptyDir:  0,
+	VolumeTypeNfs:       2,
+}
+
+// AdditionalVolume is a volume mounted in the workspace container in the form
+// of type:source:mount-path[:option]..., e.g. pvc:datasets:/data:ro or
+// emptydir:/scratch:size=50Gi. The legacy form name:mount-path mounts a pvc.
+type AdditionalVolume struct {
+	Type      string
+	Name      string
+	Server    string
+	Path      string
+	MountPath string
+	ReadOnly  bool
+	SubPath   string
+	SizeLimit string
+	Medium    string
+	// DefaultMode of the files of config maps and secrets
+	DefaultMode int
+}
+
+func ParseAdditionalVolume(value string) (AdditionalVolume, error) {
+	fields := strings.Split(value, ":")
+
+	volume := AdditionalVolume{Type: fields[0]}
+
+	sources, found := volumeSources[volume.Type]
+	if found {
+		fields = fields[1:]
+	} else if len(fields) == 2 {
+		volume.Type = VolumeTypePvc
+		sources = 1
+	} else {
+		return volume, fmt.Errorf("invalid volume %s, expected type:source:mount-path[:option]... with type one of pvc, configmap, secret, emptydir, nfs", value)
+	}
+
+	if len(fields) < sources+1 {
+		return volume, fmt.Errorf("invalid volume %s, missing source or mount path", value)
+	}
+
+	switch volume.Type {
+	case VolumeTypeNfs:
+		volume.Server = fields[0]
+		volume.Path = fields[1]
+	case VolumeTypePvc, VolumeTypeConfigMap, VolumeTypeSecret:
+		volume.Name = fields[0]
+	}
+
+	volume.MountPath = fields[sources]
+
+	for _, option := range fields[sources+1:] {
+		if err := volume.setOption(option); err != nil {
+			return volume, fmt.Errorf("invalid volume %s: %w", value, err)
+		}
+	}
+
+	if err := volume.validate(); err != nil {
+		return volume, fmt.Errorf("invalid volume %s: %w", value, err)
+	}
+
+	return volume, nil
+}
+
+func (o *AdditionalVolume) setOption(option string) error {
+	key, value, _ := strings.Cut(option, "=")
+
+	switch key {
+	case "ro":
+		o.ReadOnly = true
+	case "rw":
+		o.ReadOnly = false
+	case "subPath":
+		o.SubPath = value
+	case "size":
+		if o.Type != VolumeTypeEmptyDir {
+			return fmt.Errorf("option size is only supported by emptydir volumes")
+		}
+		if _, err := resource.ParseQuantity(value); err != nil {
+			return fmt.Errorf("invalid size %s: %w", value, err)
+		}
+		o.SizeLimit = value
+	case "medium":
+		if o.Type != VolumeTypeEmptyDir {
+			return fmt.Errorf("option medium is only supported by emptydir volumes")
+		}
+		if value != "Memory" {
+			return fmt.Errorf("invalid medium %s, only Memory is supported", value)
+		}
+		o.Medium = value
+	case "mode":
+		if o.Type != VolumeTypeConfigMap && o.Type != VolumeTypeSecret {
+			return fmt.Errorf("option mode is only supported by configmap and secret volumes")
+		}
+		mode, err := strconv.ParseUint(value, 8, 32)
+		if err != nil || mode > 0777 {
+			return fmt.Errorf("invalid mode %s, expected an octal mode (e.g. 0400)", value)
+		}
+		o.DefaultMode = int(mode)
+	default:
+		return fmt.Errorf("unknown option %s", option)
+	}
+
+	return nil
+}
+
+func (o *AdditionalVolume) validate() error {
+	if o.Name != "" {
+		if errs := validation.IsDNS1123Subdomain(o.Name); len(errs) > 0 {
+			return fmt.Errorf("invalid name %s: %s", o.Name, strings.Join(errs, ", "))
+		}
+	}
+
+	if o.Type == VolumeTypeNfs {
+		if o.Server == "" {
+			return fmt.Errorf("missing nfs server")
+		}
+		if !path.IsAbs(o.Path) {
+			return fmt.Errorf("nfs export %s must be an absolute path", o.Path)
+		}
+	}
+
+	if !path.IsAbs(o.MountPath) {
+		return fmt.Errorf("mount path %s must be an absolute path", o.MountPath)
+	}
+
+	if o.SubPath != "" {
+		if path.IsAbs(o.SubPath) || strings.HasPrefix(path.Clean(o.SubPath), "..") {
+			return fmt.Errorf("sub path %s must be a relative path inside the volume", o.SubPath)
+		}
+	}
+
+	return nil
+}
+
+// values returns the chart values of the volume
+func (o AdditionalVolume) values() map[string]interface{} {
+	values := map[string]interface{}{
+		"type":      o.Type,
+		"mountPath": o.MountPath,
+		"readOnly":  o.ReadOnly,
+	}
+
+	optional := map[string]string{
+		"name":      o.Name,
+		"server":    o.Server,
+		"path":      o.Path,
+		"subPath":   o.SubPath,
+		"sizeLimit": o.SizeLimit,
+		"medium":    o.Medium,
+	}
+
+	for key, value := range optional {
+		if value != "" {
+			values[key] = value
+		}
+	}
+
+	if o.DefaultMode != 0 {
+		values["defaultMode"] = o.DefaultMode
+	}
+
+	return values
+}
+
+// validateAdditionalVolumes parses all volumes and checks that no mount path is used twice
+func validateAdditionalVolumes(values []string) error {
+	mountPaths := map[string]string{}
+
+	for _, value := range values {
+		volume, err := ParseAdditionalVolume(value)
+		if err != nil {
+			return err
+		}
+
+		mountPath := path.Clean(volume.MountPath)
+		if reserved, found := reservedMountPaths[mountPath]; found {
+			return fmt.Errorf("volume %s can not be mounted at %s, the path is used by %s", value, mountPath, reserved)
+		}
+
+		if other, found := mountPaths[mountPath]; found {
+			return fmt.Errorf("volumes %s and %s use the same mount path", other, value)
+		}
+		mountPaths[mountPath] = value
+	}
+
+	return nil
+}
+
+func buildAdditionalVolumes(values []string) []interface{} {
+	result := []interface{}{}
+	for _, value := range values {
+		if volume, err := ParseAdditionalVolume(value); err == nil {
+			result = append(result, volume.values())
+		}
+	}
+	return result
+}
@@ -0,0 +1,34 @@
+package builder
+
+import (
+	"fmt"
+	"os"
+	"unicode/utf8"
+)
+
+// maxConfigMapFileSize keeps the files within the size limit of a configmap
+const maxConfigMapFileSize = 256 * 1024
+
+// readConfigMapFile reads a text file which is shipped to the workspace in a
+// configmap, e.g. an environment.yml or a post start script
+func readConfigMapFile(path string) (string, error) {
+	info, err := os.Stat(path)
+	if err != nil {
+		return "", err
+	}
+
+	if info.Size() > maxConfigMapFileSize {
+		return "", fmt.Errorf("%s is too large, the maximum size is %d KiB", path, maxConfigMapFileSize/1024)
+	}
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return "", err
+	}
+
+	if !utf8.Valid(data) {
+		return "", fmt.Errorf("%s is not a text file", path)
+	}
+
+	return string(data), nil
+}
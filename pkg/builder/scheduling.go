@@ -0,0 +1,97 @@
+package builder
+
+import (
+	"fmt"
+	"os"
+	"strings"
+
+	v1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/util/validation"
+	"sigs.k8s.io/yaml"
+)
+
+var tolerationEffects = []v1.TaintEffect{v1.TaintEffectNoSchedule, v1.TaintEffectPreferNoSchedule, v1.TaintEffectNoExecute}
+
+// parseToleration parses a toleration in the form of key[=value][:effect]. A
+// toleration without value tolerates all values of the key.
+func parseToleration(toleration string) (v1.Toleration, error) {
+	result := v1.Toleration{
+		Operator: v1.TolerationOpExists,
+	}
+
+	keyValue, effect, hasEffect := strings.Cut(toleration, ":")
+	key, value, hasValue := strings.Cut(keyValue, "=")
+
+	if errs := validation.IsQualifiedName(key); len(errs) > 0 {
+		return result, fmt.Errorf("invalid toleration key %s: %s", key, strings.Join(errs, ", "))
+	}
+	result.Key = key
+
+	if hasValue {
+		if errs := validation.IsValidLabelValue(value); len(errs) > 0 {
+			return result, fmt.Errorf("invalid toleration value %s: %s", value, strings.Join(errs, ", "))
+		}
+		result.Operator = v1.TolerationOpEqual
+		result.Value = value
+	}
+
+	if hasEffect {
+		for _, tolerationEffect := range tolerationEffects {
+			if effect == string(tolerationEffect) {
+				result.Effect = tolerationEffect
+			}
+		}
+
+		if result.Effect == "" {
+			return result, fmt.Errorf("invalid toleration effect %s, expected one of NoSchedule, PreferNoSchedule, NoExecute", effect)
+		}
+	}
+
+	return result, nil
+}
+
+func buildTolerations(tolerations []string) []interface{} {
+	result := []interface{}{}
+	for _, value := range tolerations {
+		toleration, err := parseToleration(value)
+		if err != nil {
+			continue
+		}
+
+		entry := map[string]interface{}{
+			"key":      toleration.Key,
+			"operator": string(toleration.Operator),
+		}
+
+		if toleration.Value != "" {
+			entry["value"] = toleration.Value
+		}
+
+		if toleration.Effect != "" {
+			entry["effect"] = string(toleration.Effect)
+		}
+
+		result = append(result, entry)
+	}
+	return result
+}
+
+// readAffinity reads the affinity of the workspace pod from a yaml or json file
+func readAffinity(path string) (map[string]interface{}, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	var affinity v1.Affinity
+	if err := yaml.UnmarshalStrict(data, &affinity); err != nil {
+		return nil, fmt.Errorf("invalid affinity in %s: %w", path, err)
+	}
+
+	values := map[string]interface{}{}
+	if err := yaml.Unmarshal(data, &values); err != nil {
+		return nil, fmt.Errorf("invalid affinity in %s: %w", path, err)
+	}
+
+	return values, nil
+}
@@ -0,0 +1,95 @@
+package builder
+
+import (
+	"fmt"
+	"net"
+	"regexp"
+	"strconv"
+	"strings"
+
+	v1 "k8s.io/api/core/v1"
+)
+
+// parseSeccompProfile parses RuntimeDefault, Unconfined or
+// Localhost/<profile>, the profile is relative to the seccomp folder of the kubelet
+func parseSeccompProfile(profile string) (v1.SeccompProfile, error) {
+	profileType, localhostProfile, hasProfile := strings.Cut(profile, "/")
+
+	switch {
+	case strings.EqualFold(profileType, string(v1.SeccompProfileTypeRuntimeDefault)) && !hasProfile:
+		return v1.SeccompProfile{Type: v1.SeccompProfileTypeRuntimeDefault}, nil
+	case strings.EqualFold(profileType, string(v1.SeccompProfileTypeUnconfined)) && !hasProfile:
+		return v1.SeccompProfile{Type: v1.SeccompProfileTypeUnconfined}, nil
+	case strings.EqualFold(profileType, string(v1.SeccompProfileTypeLocalhost)) && localhostProfile != "":
+		return v1.SeccompProfile{Type: v1.SeccompProfileTypeLocalhost, LocalhostProfile: &localhostProfile}, nil
+	}
+
+	return v1.SeccompProfile{}, fmt.Errorf("invalid seccomp profile %s, expected RuntimeDefault, Unconfined or Localhost/<profile>", profile)
+}
+
+// buildSeccompProfile returns the seccomp profile as chart value. Both keys are
+// always set as the values are merged with the values of the release. An empty
+// profile keeps the default of the container runtime.
+func buildSeccompProfile(profile string) map[string]interface{} {
+	result := map[string]interface{}{
+		"type":             "",
+		"localhostProfile": "",
+	}
+
+	if profile == "" {
+		return result
+	}
+
+	seccompProfile, _ := parseSeccompProfile(profile)
+	result["type"] = string(seccompProfile.Type)
+
+	if seccompProfile.LocalhostProfile != nil {
+		result["localhostProfile"] = *seccompProfile.LocalhostProfile
+	}
+
+	return result
+}
+
+var capabilityPattern = regexp.MustCompile(`^[A-Z][A-Z_]*$`)
+
+// normalizeCapabilities accepts capabilities with or without the CAP_ prefix
+// and in any case, kubernetes expects them without prefix
+func normalizeCapabilities(capabilities []string) []string {
+	result := make([]string, len(capabilities))
+	for index, capability := range capabilities {
+		result[index] = strings.TrimPrefix(strings.ToUpper(capability), "CAP_")
+	}
+	return result
+}
+
+func validateCapabilities(capabilities []string) error {
+	for _, capability := range normalizeCapabilities(capabilities) {
+		if !capabilityPattern.MatchString(capability) {
+			return fmt.Errorf("invalid capability %s", capability)
+		}
+	}
+	return nil
+}
+
+// validateIds checks user and group ids, empty ids keep the ids of the image
+func validateIds(ids ...string) error {
+	for _, id := range ids {
+		if id == "" {
+			continue
+		}
+
+		if value, err := strconv.ParseInt(id, 10, 64); err != nil || value < 0 {
+			return fmt.Errorf("invalid user or group id %s", id)
+		}
+	}
+	return nil
+}
+
+func validateCidrs(cidrs []string) error {
+	for _, cidr := range cidrs {
+		if _, _, err := net.ParseCIDR(cidr); err != nil {
+			return fmt.Errorf("invalid cidr %s: %w", cidr, err)
+		}
+	}
+	return nil
+}
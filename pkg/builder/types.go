@@ -19,6 +19,18 @@ func (o *Args) buildValueIfChanged(cmd *cobra.Command, value interface{}, name s
 	}
 }
 
+func (o *Args) replaceValueIfChanged(cmd *cobra.Command, value map[string]interface{}, name string, path string) {
+	if cmd.Flags().Changed(name) {
+		o.values.Replace(value, path)
+	}
+}
+
+// ReplacedPaths returns the paths of the tables which replace the tables of
+// the release, see helm.Chart.Update
+func (o *Args) ReplacedPaths() []string {
+	return o.values.ReplacedPaths()
+}
+
 func (o *Args) addPrefix(name string) string {
 	if o.Prefix != "" {
 		return o.Prefix + "-" + name
@@ -6,6 +6,9 @@ import (
 
 type Values struct {
 	values map[string]interface{}
+	// replaced are the paths of tables which replace the tables of the release
+	// instead of being merged with them
+	replaced []string
 }
 
 func NewValues() Values {
@@ -36,6 +39,30 @@ func (o *Values) Set(value interface{}, path string) {
 	o.set(value, strings.Split(path, ".")...)
 }
 
+// Replace sets a table which replaces the table of the release on update, e.g.
+// so that removed node selectors are removed from the release as well
+func (o *Values) Replace(value map[string]interface{}, path string) {
+	if value == nil {
+		value = map[string]interface{}{}
+	}
+
+	o.Set(value, path)
+	o.replaced = append(o.replaced, path)
+}
+
+func (o *Values) ReplacedPaths() []string {
+	return o.replaced
+}
+
 func (o *Values) GetMap() map[string]interface{} {
 	return o.values
 }
+
+// toValueMap converts a string map so that helm treats it as a table
+func toValueMap(values map[string]string) map[string]interface{} {
+	result := map[string]interface{}{}
+	for key, value := range values {
+		result[key] = value
+	}
+	return result
+}
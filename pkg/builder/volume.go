@@ -0,0 +1,64 @@
+package builder
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/spf13/cobra"
+	v1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+	"k8s.io/apimachinery/pkg/util/validation"
+)
+
+var accessModes = []v1.PersistentVolumeAccessMode{v1.ReadWriteOnce, v1.ReadOnlyMany, v1.ReadWriteMany, v1.ReadWriteOncePod}
+
+// VolumeArgs configure a persistent volume claim of the chart
+type VolumeArgs struct {
+	Size         string
+	StorageClass string
+	AccessModes  []string
+}
+
+// AddFlags adds the flags of the volume, the flags are named <prefix>-size etc.
+func (o *VolumeArgs) AddFlags(cmd *cobra.Command, prefix string, description string) {
+	cmd.Flags().StringVar(&o.Size, prefix+"-size", "", fmt.Sprintf("Size of the %s volume (default 25Gi)", description))
+	cmd.Flags().StringVar(&o.StorageClass, prefix+"-storage-class", "", fmt.Sprintf("Storage class of the %s volume", description))
+	cmd.Flags().StringArrayVar(&o.AccessModes, prefix+"-access-mode", []string{}, fmt.Sprintf("Access mode of the %s volume (default ReadWriteOnce), can be repeated", description))
+}
+
+func (o *VolumeArgs) buildValues(cmd *cobra.Command, args *Args, prefix string, path string) {
+	args.buildValueIfChanged(cmd, o.Size, prefix+"-size", path+".size")
+	args.buildValueIfChanged(cmd, o.StorageClass, prefix+"-storage-class", path+".storageClassName")
+	args.buildValueIfChanged(cmd, o.AccessModes, prefix+"-access-mode", path+".accessModes")
+}
+
+func (o *VolumeArgs) Validate() error {
+	if o.Size != "" {
+		if _, err := resource.ParseQuantity(o.Size); err != nil {
+			return fmt.Errorf("invalid volume size %s: %w", o.Size, err)
+		}
+	}
+
+	if o.StorageClass != "" {
+		if errs := validation.IsDNS1123Subdomain(o.StorageClass); len(errs) > 0 {
+			return fmt.Errorf("invalid storage class %s: %s", o.StorageClass, strings.Join(errs, ", "))
+		}
+	}
+
+	for _, accessMode := range o.AccessModes {
+		if !isAccessMode(accessMode) {
+			return fmt.Errorf("invalid access mode %s, expected one of ReadWriteOnce, ReadOnlyMany, ReadWriteMany, ReadWriteOncePod", accessMode)
+		}
+	}
+
+	return nil
+}
+
+func isAccessMode(value string) bool {
+	for _, accessMode := range accessModes {
+		if value == string(accessMode) {
+			return true
+		}
+	}
+	return false
+}
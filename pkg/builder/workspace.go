@@ -1,57 +1,406 @@
 package builder
 
-import "github.com/spf13/cobra"
+import (
+	"fmt"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/spf13/cobra"
+	"k8s.io/apimachinery/pkg/api/resource"
+	"k8s.io/apimachinery/pkg/util/validation"
+)
+
+// reservedLabels are set by the chart and can not be overridden
+var reservedLabels = []string{"workspace", "workspace-name", "helm.sh/chart"}
+
+// reservedLabelPrefixes are the prefixes of the common labels of the chart
+var reservedLabelPrefixes = []string{"app.kubernetes.io/"}
+
+func isReservedLabel(key string) bool {
+	for _, reservedLabel := range reservedLabels {
+		if key == reservedLabel {
+			return true
+		}
+	}
+
+	for _, prefix := range reservedLabelPrefixes {
+		if strings.HasPrefix(key, prefix) {
+			return true
+		}
+	}
+
+	return false
+}
 
 type WorkspaceArgs struct {
-	Description          string
-	RequestGpu           int
-	RequestGpuType       string
-	RequestCpu           string
-	RequestMemory        string
-	LimitCpu             string
-	LimitMemory          string
-	Image                string
-	ImageGpu             string
-	ImagePullPolicy      string
-	AdditionalVolumes    []string
-	InstallCondaPackages []string
-	InstallPipPackages   []string
+	Description           string
+	Labels                map[string]string
+	RequestGpu            int
+	RequestGpuType        string
+	RequestCpu            string
+	RequestMemory         string
+	LimitCpu              string
+	LimitMemory           string
+	Image                 string
+	ImageGpu              string
+	ImageBase             string
+	ImageBuildkit         string
+	DisableBuildkit       bool
+	BuildkitRequestCpu    string
+	BuildkitRequestMemory string
+	BuildkitLimitCpu      string
+	BuildkitLimitMemory   string
+	ImagePullPolicy       string
+	ImagePullSecrets      []string
+	AdditionalVolumes     []string
+	InstallCondaPackages  []string
+	InstallPipPackages    []string
+	CondaEnvFile          string
+	PipRequirements       string
+	DotfilesRepo          string
+	PostStartScript       string
+	Env                   []string
+	EnvFromSecrets        []string
+	EnvFromConfigMaps     []string
+	NodeSelectors         map[string]string
+	Tolerations           []string
+	AffinityFile          string
+	PriorityClass         string
+	ServiceAccount        string
+	FsGroup               int64
+	RunAsUser             string
+	RunAsGroup            string
+	SeccompProfile        string
+	AddCapabilities       []string
+	DropCapabilities      []string
+	DenyIngress           bool
+	RestrictEgress        bool
+	EgressAllowCidrs      []string
+	ShmSize               string
+	StartupTimeout        time.Duration
+	DisableProbes         bool
+	LivenessProbe         bool
+	affinity              map[string]interface{}
+	condaEnvFile          string
+	pipRequirements       string
+	postStartScript       string
+	HomeVolume            VolumeArgs
+	CondaEnvVolume        VolumeArgs
 	Args
 }
 
+// AddVolumeFlags adds the flags of the home and conda environment volumes. The
+// storage class and access modes of a persistent volume claim are immutable
+// and a resize has to be checked, so they are only added on create, see
+// workspace volume resize.
+func (o *WorkspaceArgs) AddVolumeFlags(cmd *cobra.Command) {
+	o.HomeVolume.AddFlags(cmd, o.addPrefix("home"), "home")
+	o.CondaEnvVolume.AddFlags(cmd, o.addPrefix("conda"), "conda environment")
+}
+
 func (o *WorkspaceArgs) AddFlags(cmd *cobra.Command) {
 	cmd.Flags().StringVar(&o.Description, o.addPrefix("description"), "", "Description of the workplace")
+	cmd.Flags().StringToStringVar(&o.Labels, o.addPrefix("label"), map[string]string{}, "Labels to add to the workspace (e.g. team=ml), replaces the labels set before")
 	cmd.Flags().IntVar(&o.RequestGpu, o.addPrefix("request-gpu"), 0, "The gpu resource to use")
-	cmd.Flags().StringVar(&o.RequestGpuType, o.addPrefix("request-gpu-type"), "", "The requested gpu type")
+	cmd.Flags().StringVar(&o.RequestGpuType, o.addPrefix("request-gpu-type"), "", "The requested gpu resource (e.g. nvidia.com/gpu), known resources add a matching node selector")
 	cmd.Flags().StringVar(&o.RequestCpu, o.addPrefix("request-cpu"), "", "The cpu resource to use")
 	cmd.Flags().StringVar(&o.RequestMemory, o.addPrefix("request-memory"), "", "The memory resource to use")
 	cmd.Flags().StringVar(&o.LimitCpu, o.addPrefix("limit-cpu"), "", "The cpu resource limit")
 	cmd.Flags().StringVar(&o.LimitMemory, o.addPrefix("limit-memory"), "", "The memory resource limit")
-	cmd.Flags().StringArrayVar(&o.AdditionalVolumes, o.addPrefix("volume"), []string{}, "List of additional volumes to mount in the form of volume:mount-path (e.g. volume-name:/data)")
+	cmd.Flags().StringArrayVar(&o.AdditionalVolumes, o.addPrefix("volume"), []string{}, "Volume to mount in the form of type:source:mount-path[:option]..., e.g. pvc:name:/data:ro, configmap:name:/etc/x, secret:name:/keys:mode=0400, emptydir:/scratch:size=50Gi, nfs:server:/export:/mnt or pvc:name:/data:subPath=path, can be repeated")
 	cmd.Flags().StringArrayVar(&o.InstallCondaPackages, o.addPrefix("install-conda-package"), []string{}, "List of conda-forge packages to install in the workspace")
 	cmd.Flags().StringArrayVar(&o.InstallPipPackages, o.addPrefix("install-pip-package"), []string{}, "List of pip packages to install in the workspace")
+	cmd.Flags().StringVar(&o.CondaEnvFile, o.addPrefix("conda-env-file"), "", "Conda environment file (environment.yml) or explicit lock file applied to the conda environment of the workspace, pass an empty value to remove it")
+	cmd.Flags().StringVar(&o.PipRequirements, o.addPrefix("pip-requirements"), "", "Pip requirements file installed in the conda environment of the workspace, pass an empty value to remove it")
+	cmd.Flags().StringArrayVar(&o.Env, o.addPrefix("env"), []string{}, "Environment variable to set in the workspace in the form of KEY=VALUE, can be repeated, replaces the variables set before")
+	cmd.Flags().StringArrayVar(&o.EnvFromSecrets, o.addPrefix("env-from-secret"), []string{}, "Secret whose keys are set as environment variables in the workspace, can be repeated")
+	cmd.Flags().StringArrayVar(&o.EnvFromConfigMaps, o.addPrefix("env-from-configmap"), []string{}, "ConfigMap whose keys are set as environment variables in the workspace, can be repeated")
+	cmd.Flags().StringVar(&o.DotfilesRepo, o.addPrefix("dotfiles-repo"), "", "Public git repository (https or git url) with dotfiles which is cloned to ~/.dotfiles and installed on every start of the workspace")
+	cmd.Flags().StringVar(&o.PostStartScript, o.addPrefix("post-start-script"), "", "Bash script run in the home folder on every start of the workspace, pass an empty value to remove it")
+	cmd.Flags().StringToStringVar(&o.NodeSelectors, o.addPrefix("node-selector"), map[string]string{}, "Node labels the workspace is scheduled on (e.g. nvidia.com/gpu.product=NVIDIA-A100-SXM4-40GB)")
+	cmd.Flags().StringArrayVar(&o.Tolerations, o.addPrefix("toleration"), []string{}, "Toleration of the workspace in the form of key[=value][:effect] (e.g. nvidia.com/gpu:NoSchedule), can be repeated")
+	cmd.Flags().StringVar(&o.AffinityFile, o.addPrefix("affinity-file"), "", "Yaml or json file containing the affinity of the workspace")
+	cmd.Flags().StringVar(&o.PriorityClass, o.addPrefix("priority-class"), "", "Priority class of the workspace")
+	cmd.Flags().StringVar(&o.ServiceAccount, o.addPrefix("service-account"), "", "Service account of the workspace")
+	cmd.Flags().Int64Var(&o.FsGroup, o.addPrefix("fs-group"), 1000, "Group owning the volumes of the workspace")
+	cmd.Flags().StringVar(&o.RunAsUser, o.addPrefix("run-as-user"), "", "User id the workspace container runs as, pass an empty value to use the user of the image")
+	cmd.Flags().StringVar(&o.RunAsGroup, o.addPrefix("run-as-group"), "", "Group id the workspace container runs as, pass an empty value to use the group of the image")
+	cmd.Flags().StringVar(&o.SeccompProfile, o.addPrefix("seccomp-profile"), "", "Seccomp profile of the workspace container (RuntimeDefault, Unconfined or Localhost/<profile>), pass an empty value to use the default of the container runtime")
+	cmd.Flags().StringArrayVar(&o.AddCapabilities, o.addPrefix("add-capability"), []string{}, "Linux capability added to the workspace container (e.g. SYS_PTRACE), can be repeated")
+	cmd.Flags().StringArrayVar(&o.DropCapabilities, o.addPrefix("drop-capability"), []string{}, "Linux capability dropped from the workspace container (e.g. NET_RAW or ALL), can be repeated")
+	cmd.Flags().BoolVar(&o.DenyIngress, o.addPrefix("deny-ingress"), false, "Deny all connections from other pods with a network policy, port forwards such as workspace dev keep working")
+	cmd.Flags().BoolVar(&o.RestrictEgress, o.addPrefix("restrict-egress"), false, "Only allow dns and the cidrs of --egress-allow-cidr with a network policy")
+	cmd.Flags().StringArrayVar(&o.EgressAllowCidrs, o.addPrefix("egress-allow-cidr"), []string{}, "Cidr the workspace may connect to if egress is restricted (e.g. 10.0.0.0/8), can be repeated")
+	cmd.Flags().StringVar(&o.ShmSize, o.addPrefix("shm-size"), "", "Size of the memory backed /dev/shm, mounted by default for gpu workspaces (e.g. 8Gi)")
+	cmd.Flags().DurationVar(&o.StartupTimeout, o.addPrefix("startup-timeout"), 10*time.Minute, "Time the workspace may take to start sshd before it is restarted")
+	cmd.Flags().BoolVar(&o.DisableProbes, o.addPrefix("disable-probes"), false, "Do not check if sshd is listening, the workspace is ready once the container started")
+	cmd.Flags().BoolVar(&o.LivenessProbe, o.addPrefix("liveness-probe"), false, "Restart the workspace if sshd stops responding")
 	cmd.Flags().StringVar(&o.Image, o.addPrefix("override-image"), "", "Override the workspace cpu image")
 	cmd.Flags().StringVar(&o.ImageGpu, o.addPrefix("override-image-gpu"), "", "Override the workspace gpu image")
+	cmd.Flags().StringVar(&o.ImageBase, o.addPrefix("override-image-base"), "", "Override the image of the init containers")
+	cmd.Flags().StringVar(&o.ImageBuildkit, o.addPrefix("override-image-buildkit"), "", "Override the image of the buildkit container")
+	cmd.Flags().BoolVar(&o.DisableBuildkit, o.addPrefix("disable-buildkit"), false, "Do not run the buildkit container used by workspace build")
+	cmd.Flags().StringVar(&o.BuildkitRequestCpu, o.addPrefix("buildkit-request-cpu"), "", "The cpu resource of the buildkit container")
+	cmd.Flags().StringVar(&o.BuildkitRequestMemory, o.addPrefix("buildkit-request-memory"), "", "The memory resource of the buildkit container")
+	cmd.Flags().StringVar(&o.BuildkitLimitCpu, o.addPrefix("buildkit-limit-cpu"), "", "The cpu resource limit of the buildkit container")
+	cmd.Flags().StringVar(&o.BuildkitLimitMemory, o.addPrefix("buildkit-limit-memory"), "", "The memory resource limit of the buildkit container")
 	cmd.Flags().StringVar(&o.ImagePullPolicy, o.addPrefix("image-pull-policy"), "", "Set the image pull policy")
+	cmd.Flags().StringArrayVar(&o.ImagePullSecrets, o.addPrefix("image-pull-secret"), []string{}, "Secret used to pull the images of the workspace, can be repeated (see workspace registry login)")
 }
 
 func (o *WorkspaceArgs) BuildValues(cmd *cobra.Command) map[string]interface{} {
 	o.buildValueIfChanged(cmd, o.Description, o.addPrefix("description"), "description")
+	o.replaceValueIfChanged(cmd, toValueMap(o.Labels), o.addPrefix("label"), "labels")
 	o.buildValueIfChanged(cmd, o.RequestGpu, o.addPrefix("request-gpu"), "requests.gpu")
 	o.buildValueIfChanged(cmd, o.RequestGpuType, o.addPrefix("request-gpu-type"), "requests.gpuType")
 	o.buildValueIfChanged(cmd, o.RequestCpu, o.addPrefix("request-cpu"), "requests.cpu")
 	o.buildValueIfChanged(cmd, o.RequestMemory, o.addPrefix("request-memory"), "requests.memory")
 	o.buildValueIfChanged(cmd, o.LimitCpu, o.addPrefix("limit-cpu"), "limits.cpu")
 	o.buildValueIfChanged(cmd, o.LimitMemory, o.addPrefix("limit-memory"), "limits.memory")
-	o.buildValueIfChanged(cmd, o.AdditionalVolumes, o.addPrefix("volume"), "additionalVolumes")
+	if cmd.Flags().Changed(o.addPrefix("volume")) {
+		o.values.Set(buildAdditionalVolumes(o.AdditionalVolumes), "volumes")
+		// replaces the untyped volumes of releases created by older versions
+		o.values.Set([]interface{}{}, "additionalVolumes")
+	}
 	o.buildValueIfChanged(cmd, o.InstallCondaPackages, o.addPrefix("install-conda-package"), "installCondaPackages")
 	o.buildValueIfChanged(cmd, o.InstallPipPackages, o.addPrefix("install-pip-package"), "installPipPackages")
+	o.buildValueIfChanged(cmd, o.condaEnvFile, o.addPrefix("conda-env-file"), "condaEnvFile")
+	o.buildValueIfChanged(cmd, o.pipRequirements, o.addPrefix("pip-requirements"), "pipRequirements")
+	o.buildValueIfChanged(cmd, o.DotfilesRepo, o.addPrefix("dotfiles-repo"), "dotfilesRepo")
+	o.buildValueIfChanged(cmd, o.postStartScript, o.addPrefix("post-start-script"), "postStartScript")
+	o.replaceValueIfChanged(cmd, toValueMap(parseEnv(o.Env)), o.addPrefix("env"), "env")
+	o.buildValueIfChanged(cmd, o.EnvFromSecrets, o.addPrefix("env-from-secret"), "envFromSecrets")
+	o.buildValueIfChanged(cmd, o.EnvFromConfigMaps, o.addPrefix("env-from-configmap"), "envFromConfigMaps")
+	// the node selector of the gpu type is added by the chart
+	o.replaceValueIfChanged(cmd, toValueMap(o.NodeSelectors), o.addPrefix("node-selector"), "nodeSelector")
+	o.buildValueIfChanged(cmd, buildTolerations(o.Tolerations), o.addPrefix("toleration"), "tolerations")
+	o.replaceValueIfChanged(cmd, o.affinity, o.addPrefix("affinity-file"), "affinity")
+	o.buildValueIfChanged(cmd, o.PriorityClass, o.addPrefix("priority-class"), "priorityClassName")
+	o.buildValueIfChanged(cmd, o.ServiceAccount, o.addPrefix("service-account"), "serviceAccountName")
+	o.buildValueIfChanged(cmd, o.FsGroup, o.addPrefix("fs-group"), "podSecurityContext.fsGroup")
+	o.buildValueIfChanged(cmd, o.RunAsUser, o.addPrefix("run-as-user"), "securityContext.runAsUser")
+	o.buildValueIfChanged(cmd, o.RunAsGroup, o.addPrefix("run-as-group"), "securityContext.runAsGroup")
+	o.buildValueIfChanged(cmd, buildSeccompProfile(o.SeccompProfile), o.addPrefix("seccomp-profile"), "securityContext.seccompProfile")
+	o.buildValueIfChanged(cmd, normalizeCapabilities(o.AddCapabilities), o.addPrefix("add-capability"), "securityContext.capabilities.add")
+	o.buildValueIfChanged(cmd, normalizeCapabilities(o.DropCapabilities), o.addPrefix("drop-capability"), "securityContext.capabilities.drop")
+	o.buildValueIfChanged(cmd, o.DenyIngress, o.addPrefix("deny-ingress"), "networkPolicy.denyIngress")
+	o.buildValueIfChanged(cmd, o.RestrictEgress, o.addPrefix("restrict-egress"), "networkPolicy.restrictEgress")
+	o.buildValueIfChanged(cmd, o.EgressAllowCidrs, o.addPrefix("egress-allow-cidr"), "networkPolicy.allowedEgressCidrs")
+	o.HomeVolume.buildValues(cmd, &o.Args, o.addPrefix("home"), "homeVolume")
+	o.CondaEnvVolume.buildValues(cmd, &o.Args, o.addPrefix("conda"), "condaEnvVolume")
+	o.buildValueIfChanged(cmd, o.ShmSize, o.addPrefix("shm-size"), "shmSize")
+	if cmd.Flags().Changed(o.addPrefix("startup-timeout")) {
+		o.values.Set(startupProbePeriodSeconds, "probes.startup.periodSeconds")
+		o.values.Set(startupProbeFailureThreshold(o.StartupTimeout), "probes.startup.failureThreshold")
+	}
+	o.buildValueIfChanged(cmd, !o.DisableProbes, o.addPrefix("disable-probes"), "probes.startup.enabled")
+	o.buildValueIfChanged(cmd, !o.DisableProbes, o.addPrefix("disable-probes"), "probes.readiness.enabled")
+	o.buildValueIfChanged(cmd, o.LivenessProbe, o.addPrefix("liveness-probe"), "probes.liveness.enabled")
 	o.buildValueIfChanged(cmd, o.Image, o.addPrefix("override-image"), "image")
 	o.buildValueIfChanged(cmd, o.ImageGpu, o.addPrefix("override-image-gpu"), "imageGpu")
+	o.buildValueIfChanged(cmd, o.ImageBase, o.addPrefix("override-image-base"), "imageBase")
+	o.buildValueIfChanged(cmd, o.ImageBuildkit, o.addPrefix("override-image-buildkit"), "imageBuildkit")
+	o.buildValueIfChanged(cmd, !o.DisableBuildkit, o.addPrefix("disable-buildkit"), "buildkit.enabled")
+	o.buildValueIfChanged(cmd, o.BuildkitRequestCpu, o.addPrefix("buildkit-request-cpu"), "buildkit.requests.cpu")
+	o.buildValueIfChanged(cmd, o.BuildkitRequestMemory, o.addPrefix("buildkit-request-memory"), "buildkit.requests.memory")
+	o.buildValueIfChanged(cmd, o.BuildkitLimitCpu, o.addPrefix("buildkit-limit-cpu"), "buildkit.limits.cpu")
+	o.buildValueIfChanged(cmd, o.BuildkitLimitMemory, o.addPrefix("buildkit-limit-memory"), "buildkit.limits.memory")
 	o.buildValueIfChanged(cmd, o.ImagePullPolicy, o.addPrefix("image-pull-policy"), "imagePullPolicy")
+	o.buildValueIfChanged(cmd, o.ImagePullSecrets, o.addPrefix("image-pull-secret"), "imagePullSecrets")
 	return o.values.GetMap()
 }
 
+// Complete reads the files passed to the flags, their contents are validated
+// while reading
+func (o *WorkspaceArgs) Complete() error {
+	var err error
+
+	if o.AffinityFile != "" {
+		if o.affinity, err = readAffinity(o.AffinityFile); err != nil {
+			return err
+		}
+	}
+
+	if o.CondaEnvFile != "" {
+		if o.condaEnvFile, err = readConfigMapFile(o.CondaEnvFile); err != nil {
+			return err
+		}
+	}
+
+	if o.PipRequirements != "" {
+		if o.pipRequirements, err = readConfigMapFile(o.PipRequirements); err != nil {
+			return err
+		}
+	}
+
+	if o.PostStartScript != "" {
+		if o.postStartScript, err = readConfigMapFile(o.PostStartScript); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func (o *WorkspaceArgs) Validate() error {
+	for key, value := range o.Labels {
+		if isReservedLabel(key) {
+			return fmt.Errorf("label %s is reserved", key)
+		}
+
+		if errs := validation.IsQualifiedName(key); len(errs) > 0 {
+			return fmt.Errorf("invalid label key %s: %s", key, strings.Join(errs, ", "))
+		}
+
+		if errs := validation.IsValidLabelValue(value); len(errs) > 0 {
+			return fmt.Errorf("invalid label value %s: %s", value, strings.Join(errs, ", "))
+		}
+	}
+
+	for _, env := range o.Env {
+		key, _, found := strings.Cut(env, "=")
+		if !found {
+			return fmt.Errorf("invalid environment variable %s, expected KEY=VALUE", env)
+		}
+
+		if errs := validation.IsEnvVarName(key); len(errs) > 0 {
+			return fmt.Errorf("invalid environment variable name %s: %s", key, strings.Join(errs, ", "))
+		}
+	}
+
+	for _, name := range append(append(append([]string{}, o.EnvFromSecrets...), o.EnvFromConfigMaps...), o.ImagePullSecrets...) {
+		if errs := validation.IsDNS1123Subdomain(name); len(errs) > 0 {
+			return fmt.Errorf("invalid name %s: %s", name, strings.Join(errs, ", "))
+		}
+	}
+
+	for key, value := range o.NodeSelectors {
+		if errs := validation.IsQualifiedName(key); len(errs) > 0 {
+			return fmt.Errorf("invalid node selector key %s: %s", key, strings.Join(errs, ", "))
+		}
+
+		if errs := validation.IsValidLabelValue(value); len(errs) > 0 {
+			return fmt.Errorf("invalid node selector value %s: %s", value, strings.Join(errs, ", "))
+		}
+	}
+
+	for _, toleration := range o.Tolerations {
+		if _, err := parseToleration(toleration); err != nil {
+			return err
+		}
+	}
+
+	if o.PriorityClass != "" {
+		if errs := validation.IsDNS1123Subdomain(o.PriorityClass); len(errs) > 0 {
+			return fmt.Errorf("invalid priority class %s: %s", o.PriorityClass, strings.Join(errs, ", "))
+		}
+	}
+
+	if o.ServiceAccount != "" {
+		if errs := validation.IsDNS1123Subdomain(o.ServiceAccount); len(errs) > 0 {
+			return fmt.Errorf("invalid service account %s: %s", o.ServiceAccount, strings.Join(errs, ", "))
+		}
+	}
+
+	if o.FsGroup < 0 {
+		return fmt.Errorf("invalid fs group %d", o.FsGroup)
+	}
+
+	if err := validateIds(o.RunAsUser, o.RunAsGroup); err != nil {
+		return err
+	}
+
+	if o.SeccompProfile != "" {
+		if _, err := parseSeccompProfile(o.SeccompProfile); err != nil {
+			return err
+		}
+	}
+
+	if err := validateCapabilities(append(append([]string{}, o.AddCapabilities...), o.DropCapabilities...)); err != nil {
+		return err
+	}
+
+	if err := validateCidrs(o.EgressAllowCidrs); err != nil {
+		return err
+	}
+
+	if o.StartupTimeout < startupProbePeriodSeconds*time.Second {
+		return fmt.Errorf("invalid startup timeout %s, must be at least %ds", o.StartupTimeout, startupProbePeriodSeconds)
+	}
+
+	for _, quantity := range []string{o.BuildkitRequestCpu, o.BuildkitRequestMemory, o.BuildkitLimitCpu, o.BuildkitLimitMemory} {
+		if quantity == "" {
+			continue
+		}
+
+		if _, err := resource.ParseQuantity(quantity); err != nil {
+			return fmt.Errorf("invalid buildkit resource %s: %w", quantity, err)
+		}
+	}
+
+	if o.ShmSize != "" {
+		if _, err := resource.ParseQuantity(o.ShmSize); err != nil {
+			return fmt.Errorf("invalid shm size %s: %w", o.ShmSize, err)
+		}
+	}
+
+	if err := validateAdditionalVolumes(o.AdditionalVolumes); err != nil {
+		return err
+	}
+
+	if err := o.HomeVolume.Validate(); err != nil {
+		return err
+	}
+
+	if err := o.CondaEnvVolume.Validate(); err != nil {
+		return err
+	}
+
+	if o.DotfilesRepo != "" {
+		if err := validateGitRepository(o.DotfilesRepo); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+const startupProbePeriodSeconds = 10
+
+// startupProbeFailureThreshold returns the failures of the startup probe until
+// the timeout is reached
+func startupProbeFailureThreshold(timeout time.Duration) int {
+	period := startupProbePeriodSeconds * time.Second
+	return int((timeout + period - 1) / period)
+}
+
+// validateGitRepository accepts https, http and git urls. The repository is
+// cloned by the init container without ssh keys or known hosts, so the
+// scp-like syntax (e.g. git@github.com:user/dotfiles.git) and ssh urls are
+// rejected.
+func validateGitRepository(repository string) error {
+	if parsed, err := url.Parse(repository); err == nil && parsed.Host != "" {
+		switch parsed.Scheme {
+		case "https", "http", "git":
+			return nil
+		}
+	}
+
+	return fmt.Errorf("invalid git repository %s, expected an https or git url, ssh is not supported", repository)
+}
+
+// parseEnv converts KEY=VALUE pairs to a map, later pairs take precedence
+func parseEnv(env []string) map[string]string {
+	result := map[string]string{}
+	for _, pair := range env {
+		if key, value, found := strings.Cut(pair, "="); found {
+			result[key] = value
+		}
+	}
+	return result
+}
+
 func NewWorkspaceArgs(prefix string) WorkspaceArgs {
 	return WorkspaceArgs{
 		AdditionalVolumes: []string{},
@@ -49,3 +49,90 @@ Selector labels
 app.kubernetes.io/name: {{ include "workspace.name" . }}
 app.kubernetes.io/instance: {{ .Release.Name }}
 {{- end }}
+
+{{/*
+Image of the workspace container
+*/}}
+{{- define "workspace.image" -}}
+{{- if gt (int .Values.requests.gpu) 0 }}
+{{- .Values.imageGpu }}
+{{- else }}
+{{- .Values.image }}
+{{- end }}
+{{- end }}
+
+{{/*
+Node selector of the workspace. Gpu workspaces get the node labels set by the
+device plugins and node feature discovery for the known gpu resources, the
+node selector of the values takes precedence. MIG devices are only exposed as
+separate resources by the mixed strategy.
+*/}}
+{{- define "workspace.nodeSelector" -}}
+{{- $nodeSelector := dict }}
+{{- if gt (int .Values.requests.gpu) 0 }}
+{{- $gpuType := .Values.requests.gpuType }}
+{{- if hasPrefix "nvidia.com/mig-" $gpuType }}
+{{- $_ := set $nodeSelector "nvidia.com/mig.strategy" "mixed" }}
+{{- else if eq $gpuType "nvidia.com/gpu" }}
+{{- $_ := set $nodeSelector "nvidia.com/gpu.present" "true" }}
+{{- else if eq $gpuType "gpu.intel.com/i915" }}
+{{- $_ := set $nodeSelector "intel.feature.node.kubernetes.io/gpu" "true" }}
+{{- end }}
+{{- end }}
+{{- with merge (deepCopy (.Values.nodeSelector | default dict)) $nodeSelector }}
+{{- toYaml . }}
+{{- end }}
+{{- end }}
+
+{{/*
+Security context of the workspace and its init containers built from the
+securityContext of the values, runAsUser and runAsGroup of the dict are used
+if the values do not set them
+*/}}
+{{- define "workspace.securityContext" -}}
+{{- $securityContext := .securityContext | default dict }}
+{{- $seccompProfile := $securityContext.seccompProfile | default dict }}
+{{- $capabilities := $securityContext.capabilities | default dict }}
+{{- with $securityContext.runAsUser | default .runAsUser }}
+runAsUser: {{ int64 . }}
+{{- end }}
+{{- with $securityContext.runAsGroup | default .runAsGroup }}
+runAsGroup: {{ int64 . }}
+{{- end }}
+{{- with $seccompProfile.type }}
+seccompProfile:
+  type: {{ . }}
+  {{- with $seccompProfile.localhostProfile }}
+  localhostProfile: {{ . | quote }}
+  {{- end }}
+{{- end }}
+{{- if or $capabilities.add $capabilities.drop }}
+capabilities:
+  {{- with $capabilities.add }}
+  add:
+    {{- toYaml . | nindent 4 }}
+  {{- end }}
+  {{- with $capabilities.drop }}
+  drop:
+    {{- toYaml . | nindent 4 }}
+  {{- end }}
+{{- end }}
+{{- end }}
+
+{{/*
+Environment variables taken from secrets and config maps
+*/}}
+{{- define "workspace.envFrom" -}}
+{{- range .Values.envFromSecrets }}
+- secretRef:
+    name: {{ . }}
+{{- end }}
+{{- range .Values.envFromConfigMaps }}
+- configMapRef:
+    name: {{ . }}
+{{- end }}
+# created by workspace secrets set
+- secretRef:
+    name: {{ .Release.Name }}-workspace-env
+    optional: true
+{{- end }}
@@ -1,6 +1,7 @@
 package cmd
 
 import (
+	"errors"
 	"fmt"
 	"os"
 
@@ -8,6 +9,7 @@ import (
 	"github.com/salberternst/workspace/pkg/cmd/workspace"
 	"github.com/salberternst/workspace/pkg/k8s"
 	"github.com/spf13/cobra"
+	"k8s.io/client-go/util/exec"
 )
 
 var (
@@ -45,7 +47,13 @@ func NewRootCommand() *cobra.Command {
 
 func Execute() {
 	if err := NewRootCommand().Execute(); err != nil {
-		fmt.Fprintf(os.Stderr, "Error: %s", err.Error())
+		// propagate the exit code of remote commands without printing an error
+		var exitError exec.ExitError
+		if errors.As(err, &exitError) && exitError.Exited() {
+			os.Exit(exitError.ExitStatus())
+		}
+
+		fmt.Fprintf(os.Stderr, "Error: %s\n", err.Error())
 		os.Exit(1)
 	}
 }
@@ -0,0 +1,141 @@
+package workspace
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"os/signal"
+	"syscall"
+	"time"
+
+	"github.com/salberternst/workspace/pkg/k8s"
+	"github.com/spf13/cobra"
+	"k8s.io/client-go/util/exec"
+)
+
+const (
+	DefaultSessionName = "main"
+	// a connection which lasted at least this long counts as established
+	sessionStableDuration = 10 * time.Second
+	maxReconnectAttempts  = 10
+	maxReconnectDelay     = 30 * time.Second
+)
+
+// sessionScript attaches to the tmux session or creates it. Images without tmux
+// fall back to a plain login shell which does not survive disconnects.
+const sessionScript = `if command -v tmux >/dev/null 2>&1; then
+  exec tmux new-session -A -s "$1" bash --login
+fi
+echo "tmux is not installed in the workspace, the shell will not survive disconnects" >&2
+exec bash --login`
+
+func sessionCommand(session string) []string {
+	return []string{"bash", "-c", sessionScript, "workspace-session", session}
+}
+
+// attachSession attaches the terminal to the session and reconnects if the
+// connection to the api server is lost. It returns once the session was detached
+// or the shell exited.
+func attachSession(namespace string, name string, container string, session string) error {
+	signalTermination := make(chan os.Signal, 1)
+	signal.Notify(signalTermination, syscall.SIGTERM)
+	defer signal.Stop(signalTermination)
+
+	attempts := 0
+
+	for {
+		workspacePod, err := k8s.GetWorkspacePod(namespace, name)
+		if err == nil && workspacePod == nil {
+			err = fmt.Errorf("workspace %s in namespace %s not found", name, namespace)
+		}
+
+		if err == nil {
+			connectedAt := time.Now()
+
+			err = k8s.ExecuteInPod(workspacePod.Namespace, workspacePod.Name, container, sessionCommand(session), k8s.StandardStreams(true, true))
+			if err == nil {
+				return nil
+			}
+
+			// the shell itself exited, there is nothing to reconnect to
+			var exitError exec.ExitError
+			if errors.As(err, &exitError) {
+				return err
+			}
+
+			if time.Since(connectedAt) >= sessionStableDuration {
+				attempts = 0
+			}
+		}
+
+		attempts++
+		if attempts > maxReconnectAttempts {
+			return fmt.Errorf("giving up reconnecting to session %s: %w", session, err)
+		}
+
+		delay := time.Duration(1<<(attempts-1)) * time.Second
+		if delay > maxReconnectDelay {
+			delay = maxReconnectDelay
+		}
+
+		fmt.Fprintf(os.Stderr, "\r\nConnection to session %s lost (%s), reconnecting in %s\r\n", session, err.Error(), delay)
+
+		select {
+		case <-time.After(delay):
+		case <-signalTermination:
+			return err
+		}
+	}
+}
+
+type AttachOptions struct {
+	Name      string
+	Namespace string
+	Container string
+	Session   string
+}
+
+func (o *AttachOptions) Complete(cmd *cobra.Command, args []string) error {
+	if len(args) == 0 {
+		return errors.New("missing argument: name")
+	}
+
+	var err error
+
+	o.Name = args[0]
+	o.Session = DefaultSessionName
+
+	if len(args) > 1 {
+		o.Session = args[1]
+	}
+
+	if o.Namespace, err = cmd.Flags().GetString("namespace"); err != nil {
+		return err
+	}
+
+	return nil
+}
+
+func (o *AttachOptions) Run() error {
+	return attachSession(o.Namespace, o.Name, o.Container, o.Session)
+}
+
+func NewCmdAttach() *cobra.Command {
+	options := AttachOptions{}
+
+	var command = &cobra.Command{
+		Use:   "attach name [session]",
+		Short: "Attach to a persistent terminal session of a workspace",
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if err := options.Complete(cmd, args); err != nil {
+				return err
+			}
+
+			return options.Run()
+		},
+	}
+
+	command.Flags().StringVar(&options.Container, "container", WorkspaceContainerName, "The container to attach to")
+
+	return command
+}
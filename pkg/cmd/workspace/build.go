@@ -0,0 +1,123 @@
+package workspace
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/salberternst/workspace/pkg/build"
+	"github.com/salberternst/workspace/pkg/k8s"
+	"github.com/spf13/cobra"
+	v1 "k8s.io/api/core/v1"
+)
+
+const (
+	BuildkitContainerName = "docker"
+	BuildkitContainerPort = 1234
+)
+
+type BuildOptions struct {
+	Name         string
+	Namespace    string
+	BuildOptions build.Options
+	workspacePod *v1.Pod
+}
+
+// hasBuildkit checks if the buildkit container of the workspace is running
+func hasBuildkit(pod *v1.Pod) bool {
+	for _, status := range pod.Status.ContainerStatuses {
+		if status.Name == BuildkitContainerName {
+			return status.State.Running != nil
+		}
+	}
+	return false
+}
+
+func (o *BuildOptions) Complete(cmd *cobra.Command, args []string) error {
+	if len(args) == 0 {
+		return errors.New("missing argument: name")
+	}
+
+	if len(args) > 2 {
+		return errors.New("expected arguments: name [context]")
+	}
+
+	var err error
+
+	o.Name = args[0]
+
+	o.BuildOptions.ContextPath = "."
+	if len(args) == 2 {
+		o.BuildOptions.ContextPath = args[1]
+	}
+
+	if o.Namespace, err = cmd.Flags().GetString("namespace"); err != nil {
+		return err
+	}
+
+	if o.workspacePod, err = k8s.GetWorkspacePod(o.Namespace, o.Name); err != nil {
+		return err
+	}
+
+	if o.workspacePod == nil {
+		return fmt.Errorf("workspace %s in namespace %s not found", o.Name, o.Namespace)
+	}
+
+	return nil
+}
+
+func (o *BuildOptions) Validate() error {
+	if !hasBuildkit(o.workspacePod) {
+		return fmt.Errorf("buildkit is not running in workspace %s in namespace %s, it is enabled with: workspace update %s --disable-buildkit=false", o.Name, o.Namespace, o.Name)
+	}
+
+	return o.BuildOptions.Validate()
+}
+
+func (o *BuildOptions) Run() error {
+	portForward, err := k8s.GetClient().ForwardPorts(o.workspacePod.Name, o.workspacePod.Namespace, []string{fmt.Sprintf(":%d", BuildkitContainerPort)})
+	if err != nil {
+		return err
+	}
+
+	defer close(portForward.StopChannel)
+
+	address := fmt.Sprintf("tcp://127.0.0.1:%d", portForward.ForwardedPorts[0].Local)
+
+	return build.Build(address, o.BuildOptions)
+}
+
+func NewCmdBuild() *cobra.Command {
+	options := BuildOptions{}
+
+	var command = &cobra.Command{
+		Use:   "build name [context]",
+		Short: "Build an image from a local build context with the buildkit container of a workspace",
+		Long: `Build an image from a local build context with the buildkit container of a workspace.
+
+Requires buildctl (https://github.com/moby/buildkit/releases) in the PATH, it
+sends the build context to buildkit through a port forward to the workspace.`,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if err := options.Complete(cmd, args); err != nil {
+				return err
+			}
+
+			if err := options.Validate(); err != nil {
+				return err
+			}
+
+			return options.Run()
+		},
+	}
+
+	command.Flags().StringArrayVarP(&options.BuildOptions.Tags, "tag", "t", []string{}, "Name of the image (e.g. registry.example.com/ml/train:latest), can be repeated")
+	command.Flags().StringVarP(&options.BuildOptions.Dockerfile, "file", "f", "", "Path of the Dockerfile, defaults to the Dockerfile in the build context")
+	command.Flags().StringToStringVar(&options.BuildOptions.BuildArgs, "build-arg", map[string]string{}, "Build arguments (e.g. PYTHON_VERSION=3.10)")
+	command.Flags().StringVar(&options.BuildOptions.Target, "target", "", "The build stage to build")
+	command.Flags().StringArrayVar(&options.BuildOptions.Platforms, "platform", []string{}, "Platform to build for (e.g. linux/amd64), can be repeated")
+	command.Flags().BoolVar(&options.BuildOptions.NoCache, "no-cache", false, "Do not use the cache of buildkit")
+	command.Flags().BoolVar(&options.BuildOptions.Push, "push", false, "Push the image with the local docker credentials")
+	command.Flags().StringVar(&options.BuildOptions.OutputFile, "output-file", "", "Write the image as docker archive to a local file (e.g. image.tar for docker load)")
+	command.Flags().StringVar(&options.BuildOptions.Progress, "progress", build.ProgressAuto, "Type of the progress output (auto, tty or plain)")
+
+	return command
+}
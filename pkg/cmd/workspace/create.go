@@ -37,10 +37,14 @@ func (o *CreateWorkspaceOptions) Complete(cmd *cobra.Command, args []string) err
 		return err
 	}
 
-	return nil
+	return o.args.Complete()
 }
 
 func (o *CreateWorkspaceOptions) Validate() error {
+	if err := o.args.Validate(); err != nil {
+		return err
+	}
+
 	if err := helm.ReleaseExists(o.Namespace, o.Name); err == nil {
 		return fmt.Errorf("Release %s in namespace %s already exists", o.Name, o.Namespace)
 	}
@@ -60,6 +64,8 @@ func (o *CreateWorkspaceOptions) Run(cmd *cobra.Command) error {
 			return err
 		}
 
+		fmt.Printf("Workspace %s in namespace %s starting\n", o.Name, o.Namespace)
+
 		var watcher watch.Interface
 		var err error
 
@@ -72,11 +78,11 @@ func (o *CreateWorkspaceOptions) Run(cmd *cobra.Command) error {
 			defer watcher.Stop()
 		}
 
-		if err := k8s.WaitForStatefulSetReplicaReady(o.Name, o.Namespace, o.WaitTimeoutInSeconds); err != nil {
+		if err := k8s.WaitForWorkspaceReady(o.Name, o.Namespace, o.WaitTimeoutInSeconds); err != nil {
 			return err
 		}
 
-		fmt.Printf("Workspace %s in namespace %s running\n", o.Name, o.Namespace)
+		fmt.Printf("Workspace %s in namespace %s ready\n", o.Name, o.Namespace)
 		fmt.Printf("Use: workspace dev %s --namespace %s\n", o.Name, o.Namespace)
 	}
 
@@ -112,6 +118,7 @@ func NewCmdCreateWorkspace() *cobra.Command {
 	command.Flags().UintVar(&options.WaitTimeoutInSeconds, "wait-timeout", 200, "Time to wait for workspace to get ready in seconds")
 
 	options.args.AddFlags(command)
+	options.args.AddVolumeFlags(command)
 
 	return command
 }
@@ -6,7 +6,6 @@ import (
 	"os"
 	"os/signal"
 	"strconv"
-	"strings"
 	"syscall"
 
 	"github.com/salberternst/workspace/pkg/k8s"
@@ -21,20 +20,22 @@ const PrivateKeySecretKey = "ssh_host_ecdsa_key"
 type DevOptions struct {
 	Name            string
 	Namespace       string
+	KubeConfigPath  string
+	Container       string
 	SshPort         uint16
 	DisableTerminal bool
-	Source          string
-	Target          string
-	TargetVolume    string
-	TargetFolder    string
-	SyncFolder      string
-	SyncIgnores     []string
-	Labels          map[string]string
+	Session         string
+	NoSession       bool
+	SyncArgs        SyncArgs
 	SyncWatch       bool
-	SyncMode        string
-	workspacePod    *v1.Pod
-	fileManager     *synchronization.FileManager
-	portForward     k8s.PortForward
+	SyncQuiet       bool
+	SyncJsonEvents  bool
+	// WaitTimeoutInSeconds limits how long to wait for a starting workspace
+	WaitTimeoutInSeconds uint
+	workspacePod         *v1.Pod
+	fileManager          *synchronization.FileManager
+	syncServer           *synchronization.ControlServer
+	portForward          k8s.PortForward
 }
 
 func (o *DevOptions) buildPorts() []string {
@@ -43,15 +44,6 @@ func (o *DevOptions) buildPorts() []string {
 	}
 }
 
-func (o *DevOptions) buildTarget() synchronization.Target {
-	return synchronization.Target{
-		Port:     2222,
-		Hostname: fmt.Sprintf("%s.%s.workspace", o.Name, o.Namespace),
-		Folder:   o.Target,
-		Username: "workspace",
-	}
-}
-
 func (o *DevOptions) createPortForward() error {
 	var err error
 
@@ -62,34 +54,57 @@ func (o *DevOptions) createPortForward() error {
 	return nil
 }
 
+func (o *DevOptions) syncOutputMode() synchronization.OutputMode {
+	if o.SyncJsonEvents {
+		return synchronization.OutputJsonEvents
+	}
+
+	if o.SyncQuiet {
+		return synchronization.OutputQuiet
+	}
+
+	return synchronization.OutputProgress
+}
+
+// createSynchronizationManager also serves the sessions so that they can be
+// controlled with the sync commands while dev is running
 func (o *DevOptions) createSynchronizationManager() error {
 	var err error
-	o.fileManager, err = synchronization.NewFileManager()
-	return err
-}
 
-func (o *DevOptions) setupSshConfig() error {
-	secret, err := k8s.ReadSecret(o.Name, o.Namespace)
-	if err != nil {
+	if o.syncServer, err = synchronization.Listen(o.Name, o.Namespace); err != nil {
 		return err
 	}
 
-	privateKey, ok := secret.Data[PrivateKeySecretKey]
-	if !ok {
-		return fmt.Errorf("ssh_host_ecdsa_key does not exists in secret %s in namespace %s", o.Name, o.Namespace)
+	if o.fileManager, err = synchronization.NewFileManager(o.Name, o.Namespace, o.syncOutputMode()); err != nil {
+		o.syncServer.Close()
+		return err
 	}
 
-	privateKeyPath, err := utils.WritePrivateKey(o.Name, o.Namespace, privateKey)
+	return o.syncServer.Serve(o.fileManager)
+}
+
+func (o *DevOptions) stopSynchronizationManager() {
+	o.fileManager.Stop()
+	o.syncServer.Close()
+}
+
+func setupSshConfig(name string, namespace string) error {
+	privateKey, err := readPrivateKey(name, namespace)
+	if err != nil {
+		return err
+	}
+
+	privateKeyPath, err := utils.WritePrivateKey(name, namespace, privateKey)
 	if err != nil {
 		return err
 	}
 
-	err = utils.DeleteSshConfEntry(o.Name, o.Namespace)
+	err = utils.DeleteSshConfEntry(name, namespace)
 	if err != nil {
 		return err
 	}
 
-	err = utils.AppendSshConfEntry(o.Name, o.Namespace, privateKeyPath)
+	err = utils.AppendSshConfEntry(name, namespace, privateKeyPath)
 	if err != nil {
 		return err
 	}
@@ -97,6 +112,18 @@ func (o *DevOptions) setupSshConfig() error {
 	return nil
 }
 
+// waitForWorkspaceReady waits until sshd of a starting workspace accepts connections
+func waitForWorkspaceReady(workspacePod *v1.Pod, name string, waitTimeout uint) (*v1.Pod, error) {
+	status := k8s.GetWorkspaceStatus(workspacePod)
+	if status == k8s.WorkspaceStatusReady {
+		return workspacePod, nil
+	}
+
+	fmt.Fprintf(os.Stderr, "Workspace %s in namespace %s is not ready yet (%s), waiting for it to become ready\n", name, workspacePod.Namespace, status)
+
+	return k8s.WaitForWorkspacePodReady(workspacePod.Namespace, name, waitTimeout)
+}
+
 func (o *DevOptions) Complete(cmd *cobra.Command, args []string) error {
 	if len(args) == 0 {
 		return errors.New("missing argument: name")
@@ -110,40 +137,53 @@ func (o *DevOptions) Complete(cmd *cobra.Command, args []string) error {
 		return err
 	}
 
+	if o.KubeConfigPath, err = cmd.Flags().GetString("kube-config"); err != nil {
+		return err
+	}
+
 	o.workspacePod, err = k8s.GetWorkspacePod(o.Namespace, o.Name)
 	if err != nil {
 		return err
 	}
 
-	if o.SyncFolder != "" {
-		target := strings.Split(o.SyncFolder, ":")
-		if len(target) != 2 {
-			return fmt.Errorf("Invalid sync folder %s", o.SyncFolder)
-		}
+	if o.workspacePod == nil {
+		return fmt.Errorf("workspace %s in namespace %s not found", o.Name, o.Namespace)
+	}
 
-		o.Source = target[0]
-		o.Target = target[1]
+	if o.workspacePod, err = waitForWorkspaceReady(o.workspacePod, o.Name, o.WaitTimeoutInSeconds); err != nil {
+		return err
+	}
 
-		if err := o.createSynchronizationManager(); err != nil {
-			return err
-		}
+	if err := o.SyncArgs.Complete(); err != nil {
+		return err
+	}
+
+	// the exec transport neither requires sshd nor an ssh config entry
+	if o.SyncArgs.Transport == synchronization.TransportExec {
+		return nil
 	}
 
-	return o.setupSshConfig()
+	return setupSshConfig(o.Name, o.Namespace)
 }
 
 func (o *DevOptions) Run() error {
-	if err := o.createPortForward(); err != nil {
-		return err
+	// the exec transport has to keep working if sshd or the local port is not available
+	if o.SyncArgs.Transport != synchronization.TransportExec {
+		if err := o.createPortForward(); err != nil {
+			return err
+		}
 	}
 
-	if o.SyncFolder != "" {
-		if err := o.fileManager.Run(o.Source, o.buildTarget(), o.SyncIgnores, o.Labels, o.SyncWatch, o.SyncMode); err != nil {
+	if len(o.SyncArgs.folders) > 0 {
+		if err := o.createSynchronizationManager(); err != nil {
 			return err
 		}
-	}
+		defer o.stopSynchronizationManager()
 
-	defer o.fileManager.Stop()
+		if err := o.fileManager.Run(o.SyncArgs.folders, o.SyncArgs.buildTarget(o.Name, o.Namespace, o.KubeConfigPath, o.SshPort), o.SyncArgs.Ignores, o.SyncArgs.Labels, o.SyncWatch, o.SyncArgs.SessionConfig(), o.SyncArgs.Timeout); err != nil {
+			return err
+		}
+	}
 
 	if o.DisableTerminal {
 		signalTermination := make(chan os.Signal, 1)
@@ -151,15 +191,15 @@ func (o *DevOptions) Run() error {
 
 		fmt.Println("Press CTRL+C to stop")
 
-		select {
-		case <-o.portForward.StopChannel:
-			return nil
-		case <-signalTermination:
-			return nil
-		}
+		<-signalTermination
+		return nil
 	}
 
-	return k8s.ExecuteInPod(o.workspacePod.Namespace, o.workspacePod.Name, "workspace", []string{"bash", "--login"}, true)
+	if o.NoSession {
+		return k8s.ExecuteInPod(o.workspacePod.Namespace, o.workspacePod.Name, o.Container, []string{"bash", "--login"}, k8s.StandardStreams(true, true))
+	}
+
+	return attachSession(o.Namespace, o.Name, o.Container, o.Session)
 }
 
 func NewCmdDev() *cobra.Command {
@@ -173,19 +213,24 @@ func NewCmdDev() *cobra.Command {
 			}
 
 			fmt.Println(utils.Logo)
-			fmt.Printf("Connect via: ssh %s.%s.workspace\n", options.Name, options.Namespace)
+			if options.SyncArgs.Transport != synchronization.TransportExec {
+				fmt.Printf("Connect via: ssh %s.%s.workspace\n", options.Name, options.Namespace)
+			}
 
 			return options.Run()
 		},
 	}
 
+	command.Flags().StringVar(&options.Container, "container", WorkspaceContainerName, "The container to start the terminal in")
 	command.Flags().Uint16Var(&options.SshPort, "ssh-port", 2222, "The local ssh port")
 	command.Flags().BoolVar(&options.DisableTerminal, "disable-terminal", false, "Disable the terminal")
-	command.Flags().StringArrayVar(&options.SyncIgnores, "sync-ignore", []string{".mutagen", ".git"}, "List of folders and files to ignore")
-	command.Flags().StringToStringVar(&options.Labels, "sync-label", map[string]string{}, "List of custom labels to add")
+	command.Flags().StringVar(&options.Session, "session", DefaultSessionName, "Name of the persistent terminal session to attach to")
+	command.Flags().BoolVar(&options.NoSession, "no-session", false, "Start a plain shell which does not survive disconnects")
+	command.Flags().UintVar(&options.WaitTimeoutInSeconds, "wait-timeout", 300, "Time to wait for a starting workspace to get ready in seconds")
 	command.Flags().BoolVar(&options.SyncWatch, "sync-watch", false, "Continuously synchronize file changes to the workspace")
-	command.Flags().StringVar(&options.SyncMode, "sync-mode", "twowaysafe", "Set the synchonization mode see https://mutagen.io/documentation/synchronization")
-	command.Flags().StringVar(&options.SyncFolder, "sync-folder", "", "Synchronize a folder to the workspace")
+	command.Flags().BoolVar(&options.SyncQuiet, "sync-quiet", false, "Do not show the synchronization progress")
+	command.Flags().BoolVar(&options.SyncJsonEvents, "sync-json-events", false, "Print the synchronization progress as json object per line to stdout")
+	options.SyncArgs.AddFlags(command)
 
 	return command
 }
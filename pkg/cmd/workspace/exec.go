@@ -3,65 +3,205 @@ package workspace
 import (
 	"errors"
 	"fmt"
-	"strings"
+	"os"
+	"sync"
 
+	"github.com/fatih/color"
+	"github.com/jedib0t/go-pretty/v6/table"
 	"github.com/salberternst/workspace/pkg/k8s"
+	"github.com/salberternst/workspace/pkg/utils"
 	"github.com/spf13/cobra"
 	v1 "k8s.io/api/core/v1"
+	"k8s.io/client-go/util/exec"
 )
 
+const WorkspaceContainerName = "workspace"
+
 type ExecOptions struct {
-	Name         string
-	Namespace    string
-	Command      []string
-	Tty          bool
-	workspacePod *v1.Pod
+	Name          string
+	Namespace     string
+	Container     string
+	Selector      string
+	Parallelism   int
+	Command       []string
+	Stdin         bool
+	Tty           bool
+	workspacePods []v1.Pod
+}
+
+type execResult struct {
+	workspace string
+	err       error
 }
 
 func (o *ExecOptions) Complete(cmd *cobra.Command, args []string, argsLengthAtDash int) error {
-	if len(args) == 0 {
+	if o.Selector == "" && (len(args) == 0 || argsLengthAtDash == 0) {
 		return errors.New("missing argument: name")
 	}
 
-	if argsLengthAtDash <= 0 {
+	if o.Selector != "" && argsLengthAtDash > 0 {
+		return errors.New("a name can not be used together with --selector")
+	}
+
+	if argsLengthAtDash < 0 || argsLengthAtDash == len(args) {
 		return errors.New("you must specify a command")
 	}
 
 	var err error
 
-	o.Name = args[0]
-
 	if o.Namespace, err = cmd.Flags().GetString("namespace"); err != nil {
 		return err
 	}
 
-	o.Command = append([]string{"bash", "-c"}, strings.Join(args[argsLengthAtDash:], " "))
+	// the arguments are passed as is, use e.g. -- bash -c "..." for shell features
+	o.Command = args[argsLengthAtDash:]
 
-	if o.workspacePod, err = k8s.GetWorkspacePod(o.Namespace, o.Name); err != nil {
+	if o.Selector != "" {
+		if o.workspacePods, err = k8s.GetWorkspacePods(o.Namespace, o.Selector); err != nil {
+			return err
+		}
+
+		if len(o.workspacePods) == 0 {
+			return fmt.Errorf("no workspaces matching %s found in namespace %s", o.Selector, o.Namespace)
+		}
+
+		return nil
+	}
+
+	o.Name = args[0]
+
+	workspacePod, err := k8s.GetWorkspacePod(o.Namespace, o.Name)
+	if err != nil {
 		return err
 	}
 
-	if o.workspacePod == nil {
-		return fmt.Errorf("workspace %s in namespace %s not found\n", o.Name, o.Namespace)
+	if workspacePod == nil {
+		return fmt.Errorf("workspace %s in namespace %s not found", o.Name, o.Namespace)
+	}
+
+	o.workspacePods = []v1.Pod{*workspacePod}
+
+	return nil
+}
+
+func (o *ExecOptions) Validate() error {
+	if o.Selector != "" && (o.Stdin || o.Tty) {
+		return errors.New("--stdin and --tty can not be used together with --selector")
+	}
+
+	if o.Parallelism < 1 {
+		return errors.New("--parallelism must be at least 1")
 	}
 
 	return nil
 }
 
 func (o *ExecOptions) Run() error {
-	return k8s.ExecuteInPod(o.workspacePod.Namespace, o.workspacePod.Name, "workspace", o.Command, o.Tty)
+	if o.Selector == "" {
+		return k8s.ExecuteInPod(o.workspacePods[0].Namespace, o.workspacePods[0].Name, o.Container, o.Command, k8s.StandardStreams(o.Stdin, o.Tty))
+	}
+
+	results := o.runAll()
+
+	printExecResults(results)
+
+	failed := 0
+	for _, result := range results {
+		if result.err != nil {
+			failed++
+		}
+	}
+
+	if failed > 0 {
+		return fmt.Errorf("command failed in %d of %d workspaces", failed, len(results))
+	}
+
+	return nil
+}
+
+// runAll executes the command in all selected workspaces with the output of each
+// workspace prefixed by its name
+func (o *ExecOptions) runAll() []execResult {
+	results := make([]execResult, len(o.workspacePods))
+	semaphore := make(chan struct{}, o.Parallelism)
+
+	var stdoutMutex, stderrMutex sync.Mutex
+	var wg sync.WaitGroup
+
+	for index, pod := range o.workspacePods {
+		wg.Add(1)
+
+		go func(index int, pod v1.Pod) {
+			defer wg.Done()
+
+			semaphore <- struct{}{}
+			defer func() { <-semaphore }()
+
+			workspace := pod.Labels["workspace-name"]
+			prefix := color.CyanString("[%s]", workspace) + " "
+
+			stdout := utils.NewPrefixWriter(os.Stdout, &stdoutMutex, prefix)
+			stderr := utils.NewPrefixWriter(os.Stderr, &stderrMutex, prefix)
+
+			err := k8s.ExecuteInPod(pod.Namespace, pod.Name, o.Container, o.Command, k8s.ExecStreams{
+				Stdout: stdout,
+				Stderr: stderr,
+			})
+
+			stdout.Flush()
+			stderr.Flush()
+
+			results[index] = execResult{
+				workspace: workspace,
+				err:       err,
+			}
+		}(index, pod)
+	}
+
+	wg.Wait()
+
+	return results
+}
+
+func printExecResults(results []execResult) {
+	t := table.NewWriter()
+	t.SetOutputMirror(os.Stdout)
+	t.AppendHeader(table.Row{"Workspace", "Exit Code", "Error"})
+
+	for _, result := range results {
+		exitCode := "0"
+		message := ""
+
+		if result.err != nil {
+			var exitError exec.ExitError
+			if errors.As(result.err, &exitError) && exitError.Exited() {
+				exitCode = fmt.Sprint(exitError.ExitStatus())
+			} else {
+				exitCode = "-"
+				message = result.err.Error()
+			}
+		}
+
+		t.AppendRow(table.Row{result.workspace, exitCode, message})
+	}
+
+	t.Render()
 }
 
 func NewCmdExecWorkspace() *cobra.Command {
 	options := ExecOptions{}
 
 	var command = &cobra.Command{
-		Use: "exec name -- ...args",
+		Use: "exec [name] -- ...args",
 		RunE: func(cmd *cobra.Command, args []string) error {
 			if err := options.Complete(cmd, args, cmd.ArgsLenAtDash()); err != nil {
 				return err
 			}
 
+			if err := options.Validate(); err != nil {
+				return err
+			}
+
 			if err := options.Run(); err != nil {
 				return err
 			}
@@ -70,7 +210,11 @@ func NewCmdExecWorkspace() *cobra.Command {
 		},
 	}
 
-	command.Flags().BoolVar(&options.Tty, "tty", false, "Stdin is a TTY")
+	command.Flags().BoolVar(&options.Stdin, "stdin", false, "Pass stdin to the command")
+	command.Flags().BoolVar(&options.Tty, "tty", false, "Stdin is a TTY (implies --stdin)")
+	command.Flags().StringVar(&options.Container, "container", WorkspaceContainerName, "The container to execute the command in (e.g. docker)")
+	command.Flags().StringVar(&options.Selector, "selector", "", "Execute the command in all workspaces matching the label selector (e.g. team=ml)")
+	command.Flags().IntVar(&options.Parallelism, "parallelism", 10, "Maximum number of workspaces to execute the command in at once when using --selector")
 
 	return command
 }
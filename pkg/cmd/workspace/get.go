@@ -5,6 +5,8 @@ import (
 	"errors"
 	"fmt"
 	"os"
+	"sort"
+	"strings"
 
 	"github.com/jedib0t/go-pretty/v6/table"
 	"github.com/jedib0t/go-pretty/v6/text"
@@ -25,6 +27,32 @@ func getWorkspaceContainer(containers []corev1.Container) *corev1.Container {
 	return nil
 }
 
+// isGpuResource checks if the resource is provided by a gpu device plugin
+func isGpuResource(name corev1.ResourceName) bool {
+	return strings.HasPrefix(string(name), "nvidia.com/") ||
+		strings.HasPrefix(string(name), "amd.com/") ||
+		strings.HasPrefix(string(name), "gpu.intel.com/") ||
+		strings.Contains(string(name), "gpu")
+}
+
+// describeGpuLimit returns the gpu limits together with their resource, the
+// resource depends on the gpu type of the workspace
+func describeGpuLimit(limits corev1.ResourceList) string {
+	var gpus []string
+	for name, quantity := range limits {
+		if isGpuResource(name) {
+			gpus = append(gpus, fmt.Sprintf("%s (%s)", quantity.String(), name))
+		}
+	}
+
+	if len(gpus) == 0 {
+		return "0"
+	}
+
+	sort.Strings(gpus)
+	return strings.Join(gpus, ", ")
+}
+
 type GetWorkspaceOptions struct {
 	Name           string
 	Namespace      string
@@ -100,17 +128,16 @@ func printWorkspace(workspacePod corev1.Pod) error {
 	t.AppendRows([]table.Row{
 		{"Name", workspacePod.Name},
 		{"Namespace", workspacePod.Namespace},
+		{"Status", k8s.GetWorkspaceStatus(&workspacePod)},
 		{"Created At", workspacePod.CreationTimestamp.Local()},
 	})
 	t.AppendSeparator()
 	t.AppendRow(table.Row{"Limits"})
 	t.AppendSeparator()
-	// fix: use gpu type
-	gpuLimit := workspaceContainer.Resources.Limits["nvidia.com/gpu"]
 	t.AppendRows([]table.Row{
 		{"CPU", workspaceContainer.Resources.Limits.Cpu().String()},
 		{"Memory", workspaceContainer.Resources.Limits.Memory().String()},
-		{"GPU", gpuLimit.String()},
+		{"GPU", describeGpuLimit(workspaceContainer.Resources.Limits)},
 	})
 	t.AppendSeparator()
 	t.AppendRow(table.Row{"Requests"})
@@ -124,6 +151,10 @@ func printWorkspace(workspacePod corev1.Pod) error {
 	t.AppendSeparator()
 	for _, volume := range workspacePod.Spec.Volumes {
 		volumeMountIndex := slices.IndexFunc(workspaceContainer.VolumeMounts, func(c corev1.VolumeMount) bool { return c.Name == volume.Name })
+		// volumes of other containers
+		if volumeMountIndex < 0 {
+			continue
+		}
 		t.AppendRow(table.Row{
 			volume.Name,
 			workspaceContainer.VolumeMounts[volumeMountIndex].MountPath,
@@ -9,6 +9,7 @@ import (
 	"github.com/salberternst/workspace/pkg/k8s"
 	"github.com/spf13/cobra"
 	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
 	v1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 )
 
@@ -49,20 +50,36 @@ func (o *ListWorkspaceOptions) Run() error {
 		return nil
 	}
 
-	printWorkspaces(workspaces)
+	pods, err := k8s.GetWorkspacePods(o.Namespace, "")
+	if err != nil {
+		return err
+	}
+
+	printWorkspaces(workspaces, pods)
 
 	return nil
 }
 
-func printWorkspaces(workspaces *appsv1.StatefulSetList) {
+// workspaceStatus returns the status of the pod of the workspace
+func workspaceStatus(name string, pods []corev1.Pod) string {
+	for index := range pods {
+		if pods[index].Labels["workspace-name"] == name {
+			return k8s.GetWorkspaceStatus(&pods[index])
+		}
+	}
+	return "Stopped"
+}
+
+func printWorkspaces(workspaces *appsv1.StatefulSetList, pods []corev1.Pod) {
 	t := table.NewWriter()
 	t.SetOutputMirror(os.Stdout)
-	t.AppendHeader(table.Row{"Name", "Replicas Ready", "Created At", "Description"})
+	t.AppendHeader(table.Row{"Name", "Status", "Replicas Ready", "Created At", "Description"})
 
 	for _, workspace := range workspaces.Items {
 		t.AppendRows([]table.Row{
 			{
 				workspace.Name,
+				workspaceStatus(workspace.Name, pods),
 				fmt.Sprintf("%d/%d", workspace.Status.ReadyReplicas, *workspace.Spec.Replicas),
 				workspace.CreationTimestamp.Local(),
 				workspace.ObjectMeta.Annotations["workspace-description"],
@@ -0,0 +1,116 @@
+package workspace
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/salberternst/workspace/pkg/k8s"
+	"github.com/spf13/cobra"
+	v1 "k8s.io/api/core/v1"
+)
+
+// PostStartContainerName is the init container running the dotfiles and the
+// post start script
+const PostStartContainerName = "post-start"
+
+type LogsOptions struct {
+	Name         string
+	Namespace    string
+	Container    string
+	PostStart    bool
+	Follow       bool
+	Previous     bool
+	Tail         int64
+	workspacePod *v1.Pod
+}
+
+// hasContainer checks if the pod has a container or init container with the name
+func hasContainer(pod *v1.Pod, name string) bool {
+	for _, container := range append(append([]v1.Container{}, pod.Spec.InitContainers...), pod.Spec.Containers...) {
+		if container.Name == name {
+			return true
+		}
+	}
+	return false
+}
+
+func (o *LogsOptions) Complete(cmd *cobra.Command, args []string) error {
+	if len(args) == 0 {
+		return errors.New("missing argument: name")
+	}
+
+	var err error
+
+	o.Name = args[0]
+
+	if o.Namespace, err = cmd.Flags().GetString("namespace"); err != nil {
+		return err
+	}
+
+	if o.PostStart {
+		o.Container = PostStartContainerName
+	}
+
+	if o.workspacePod, err = k8s.GetWorkspacePod(o.Namespace, o.Name); err != nil {
+		return err
+	}
+
+	if o.workspacePod == nil {
+		return fmt.Errorf("workspace %s in namespace %s not found", o.Name, o.Namespace)
+	}
+
+	return nil
+}
+
+func (o *LogsOptions) Validate() error {
+	if !hasContainer(o.workspacePod, o.Container) {
+		if o.Container == PostStartContainerName {
+			return fmt.Errorf("workspace %s in namespace %s has neither a dotfiles repository nor a post start script", o.Name, o.Namespace)
+		}
+		return fmt.Errorf("container %s not found in workspace %s in namespace %s", o.Container, o.Name, o.Namespace)
+	}
+
+	return nil
+}
+
+func (o *LogsOptions) Run() error {
+	podLogOpts := v1.PodLogOptions{
+		Container: o.Container,
+		Follow:    o.Follow,
+		Previous:  o.Previous,
+	}
+
+	if o.Tail >= 0 {
+		podLogOpts.TailLines = &o.Tail
+	}
+
+	return k8s.GetPodLogs(o.workspacePod, podLogOpts)
+}
+
+func NewCmdLogs() *cobra.Command {
+	options := LogsOptions{}
+
+	var command = &cobra.Command{
+		Use:   "logs name",
+		Short: "Print the logs of a container of a workspace",
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if err := options.Complete(cmd, args); err != nil {
+				return err
+			}
+
+			if err := options.Validate(); err != nil {
+				return err
+			}
+
+			return options.Run()
+		},
+	}
+
+	command.Flags().StringVar(&options.Container, "container", WorkspaceContainerName, "The container to print the logs of (e.g. post-start, install-pip-packages or docker)")
+	command.Flags().BoolVar(&options.PostStart, "post-start", false, "Print the logs of the dotfiles and the post start script, same as --container=post-start")
+	command.Flags().BoolVarP(&options.Follow, "follow", "f", false, "Stream the logs")
+	command.Flags().BoolVar(&options.Previous, "previous", false, "Print the logs of the previous instance of the container, e.g. after it failed")
+	command.Flags().Int64Var(&options.Tail, "tail", -1, "Number of lines to print from the end of the logs, all lines by default")
+
+	return command
+}
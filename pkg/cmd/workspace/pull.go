@@ -0,0 +1,68 @@
+package workspace
+
+import (
+	"errors"
+
+	"github.com/salberternst/workspace/pkg/transfer"
+	"github.com/spf13/cobra"
+)
+
+type PullOptions struct {
+	Name         string
+	Namespace    string
+	RemotePath   string
+	LocalPath    string
+	TransferArgs TransferArgs
+	options      transfer.Options
+	client       *transfer.Client
+}
+
+func (o *PullOptions) Complete(cmd *cobra.Command, args []string) error {
+	if len(args) != 3 {
+		return errors.New("expected arguments: name remote-path local-path")
+	}
+
+	var err error
+
+	o.Name = args[0]
+	o.RemotePath = args[1]
+	o.LocalPath = args[2]
+
+	if o.Namespace, err = cmd.Flags().GetString("namespace"); err != nil {
+		return err
+	}
+
+	if o.options, err = o.TransferArgs.Options(); err != nil {
+		return err
+	}
+
+	if o.client, err = o.TransferArgs.Client(o.Name, o.Namespace); err != nil {
+		return err
+	}
+
+	return nil
+}
+
+func (o *PullOptions) Run() error {
+	return o.client.Pull(o.RemotePath, o.LocalPath, o.options)
+}
+
+func NewCmdPull() *cobra.Command {
+	options := PullOptions{}
+
+	var command = &cobra.Command{
+		Use:   "pull name remote-path local-path",
+		Short: "Copy a file or directory from a workspace",
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if err := options.Complete(cmd, args); err != nil {
+				return err
+			}
+
+			return options.Run()
+		},
+	}
+
+	options.TransferArgs.AddFlags(command)
+
+	return command
+}
@@ -0,0 +1,111 @@
+package workspace
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/dustin/go-humanize"
+	"github.com/salberternst/workspace/pkg/k8s"
+	"github.com/salberternst/workspace/pkg/transfer"
+	"github.com/spf13/cobra"
+)
+
+const defaultResumeThreshold = "64MiB"
+
+// TransferArgs are the flags shared by push and pull
+type TransferArgs struct {
+	Container       string
+	Compress        bool
+	ResumeThreshold string
+}
+
+func (o *TransferArgs) AddFlags(cmd *cobra.Command) {
+	cmd.Flags().StringVar(&o.Container, "container", WorkspaceContainerName, "The container to copy the files from or to")
+	cmd.Flags().BoolVar(&o.Compress, "compress", false, "Compress the data with gzip while it is transferred")
+	cmd.Flags().StringVar(&o.ResumeThreshold, "resume-threshold", defaultResumeThreshold, "Files larger than this are transferred one by one and resumed if the transfer is interrupted")
+}
+
+func (o *TransferArgs) Options() (transfer.Options, error) {
+	threshold, err := humanize.ParseBytes(o.ResumeThreshold)
+	if err != nil {
+		return transfer.Options{}, fmt.Errorf("Invalid value %s for --resume-threshold: %w", o.ResumeThreshold, err)
+	}
+
+	return transfer.Options{
+		Compress:        o.Compress,
+		ResumeThreshold: int64(threshold),
+	}, nil
+}
+
+func (o *TransferArgs) Client(name string, namespace string) (*transfer.Client, error) {
+	workspacePod, err := k8s.GetWorkspacePod(namespace, name)
+	if err != nil {
+		return nil, err
+	}
+
+	if workspacePod == nil {
+		return nil, fmt.Errorf("workspace %s in namespace %s not found", name, namespace)
+	}
+
+	return transfer.NewClient(workspacePod.Namespace, workspacePod.Name, o.Container), nil
+}
+
+type PushOptions struct {
+	Name         string
+	Namespace    string
+	LocalPath    string
+	RemotePath   string
+	TransferArgs TransferArgs
+	options      transfer.Options
+	client       *transfer.Client
+}
+
+func (o *PushOptions) Complete(cmd *cobra.Command, args []string) error {
+	if len(args) != 3 {
+		return errors.New("expected arguments: name local-path remote-path")
+	}
+
+	var err error
+
+	o.Name = args[0]
+	o.LocalPath = args[1]
+	o.RemotePath = args[2]
+
+	if o.Namespace, err = cmd.Flags().GetString("namespace"); err != nil {
+		return err
+	}
+
+	if o.options, err = o.TransferArgs.Options(); err != nil {
+		return err
+	}
+
+	if o.client, err = o.TransferArgs.Client(o.Name, o.Namespace); err != nil {
+		return err
+	}
+
+	return nil
+}
+
+func (o *PushOptions) Run() error {
+	return o.client.Push(o.LocalPath, o.RemotePath, o.options)
+}
+
+func NewCmdPush() *cobra.Command {
+	options := PushOptions{}
+
+	var command = &cobra.Command{
+		Use:   "push name local-path remote-path",
+		Short: "Copy a local file or directory into a workspace",
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if err := options.Complete(cmd, args); err != nil {
+				return err
+			}
+
+			return options.Run()
+		},
+	}
+
+	options.TransferArgs.AddFlags(command)
+
+	return command
+}
@@ -0,0 +1,183 @@
+package workspace
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+
+	"github.com/docker/cli/cli/config"
+	"github.com/salberternst/workspace/pkg/k8s"
+	"github.com/spf13/cobra"
+	"k8s.io/apimachinery/pkg/util/validation"
+)
+
+// dockerHubRegistry is the key of docker hub in docker config files
+const dockerHubRegistry = "https://index.docker.io/v1/"
+
+type RegistryLoginOptions struct {
+	Registry      string
+	Namespace     string
+	SecretName    string
+	Username      string
+	PasswordStdin bool
+	password      string
+}
+
+// normalizeRegistry returns the key of the registry in docker config files
+func normalizeRegistry(registry string) string {
+	switch registry {
+	case "docker.io", "index.docker.io", "registry-1.docker.io":
+		return dockerHubRegistry
+	}
+	return registry
+}
+
+// defaultSecretName derives the secret name from the registry, e.g. registry-ghcr.io
+func defaultSecretName(registry string) string {
+	if registry == dockerHubRegistry {
+		return "registry-docker.io"
+	}
+
+	name := strings.TrimPrefix(strings.TrimPrefix(registry, "https://"), "http://")
+	name = strings.ToLower(strings.NewReplacer(":", "-", "/", "-", "_", "-").Replace(name))
+
+	return "registry-" + strings.Trim(name, "-.")
+}
+
+func (o *RegistryLoginOptions) Complete(cmd *cobra.Command, args []string) error {
+	if len(args) == 0 {
+		return errors.New("missing argument: registry")
+	}
+
+	var err error
+
+	o.Registry = normalizeRegistry(args[0])
+
+	if o.Namespace, err = cmd.Flags().GetString("namespace"); err != nil {
+		return err
+	}
+
+	if o.SecretName == "" {
+		o.SecretName = defaultSecretName(o.Registry)
+	}
+
+	if o.PasswordStdin {
+		password, err := io.ReadAll(os.Stdin)
+		if err != nil {
+			return err
+		}
+		o.password = strings.TrimRight(string(password), "\r\n")
+	}
+
+	return nil
+}
+
+func (o *RegistryLoginOptions) Validate() error {
+	if errs := validation.IsDNS1123Subdomain(o.SecretName); len(errs) > 0 {
+		return fmt.Errorf("Invalid secret name %s: %s", o.SecretName, strings.Join(errs, ", "))
+	}
+
+	if o.PasswordStdin && o.Username == "" {
+		return errors.New("--password-stdin requires --username")
+	}
+
+	return nil
+}
+
+// readCredentials reads the credentials of the registry from the local docker
+// config, including credential helpers
+func (o *RegistryLoginOptions) readCredentials() (string, string, error) {
+	if o.Username != "" {
+		return o.Username, o.password, nil
+	}
+
+	configFile, err := config.Load(config.Dir())
+	if err != nil {
+		return "", "", fmt.Errorf("Failed to load the docker config: %w", err)
+	}
+
+	authConfig, err := configFile.GetAuthConfig(o.Registry)
+	if err != nil {
+		return "", "", fmt.Errorf("Failed to read the credentials of %s: %w", o.Registry, err)
+	}
+
+	if authConfig.IdentityToken != "" && authConfig.Password == "" {
+		return "", "", fmt.Errorf("The docker credentials of %s are an identity token which can not be used to pull images, use --username and --password-stdin", o.Registry)
+	}
+
+	if authConfig.Username == "" || authConfig.Password == "" {
+		return "", "", fmt.Errorf("No docker credentials found for %s, run docker login %s first or use --username and --password-stdin", o.Registry, o.Registry)
+	}
+
+	return authConfig.Username, authConfig.Password, nil
+}
+
+func (o *RegistryLoginOptions) Run() error {
+	username, password, err := o.readCredentials()
+	if err != nil {
+		return err
+	}
+
+	dockerConfigJson, err := json.Marshal(map[string]interface{}{
+		"auths": map[string]interface{}{
+			o.Registry: map[string]string{
+				"username": username,
+				"password": password,
+				"auth":     base64.StdEncoding.EncodeToString([]byte(username + ":" + password)),
+			},
+		},
+	})
+	if err != nil {
+		return err
+	}
+
+	if err := k8s.ApplyDockerRegistrySecret(o.SecretName, o.Namespace, dockerConfigJson); err != nil {
+		return err
+	}
+
+	fmt.Printf("Stored the credentials of %s in secret %s in namespace %s\n", o.Registry, o.SecretName, o.Namespace)
+	fmt.Printf("Use: workspace create name --namespace %s --image-pull-secret %s\n", o.Namespace, o.SecretName)
+
+	return nil
+}
+
+func NewCmdRegistryLogin() *cobra.Command {
+	options := RegistryLoginOptions{}
+
+	var command = &cobra.Command{
+		Use:   "login registry",
+		Short: "Create an image pull secret from the local docker credentials of a registry",
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if err := options.Complete(cmd, args); err != nil {
+				return err
+			}
+
+			if err := options.Validate(); err != nil {
+				return err
+			}
+
+			return options.Run()
+		},
+	}
+
+	command.Flags().StringVar(&options.SecretName, "secret-name", "", "Name of the image pull secret (default registry-<registry>)")
+	command.Flags().StringVar(&options.Username, "username", "", "Use this username instead of the local docker credentials")
+	command.Flags().BoolVar(&options.PasswordStdin, "password-stdin", false, "Read the password of --username from stdin")
+
+	return command
+}
+
+func NewCmdRegistry() *cobra.Command {
+	var command = &cobra.Command{
+		Use:   "registry",
+		Short: "Manage the credentials of private image registries",
+	}
+
+	command.AddCommand(NewCmdRegistryLogin())
+
+	return command
+}
@@ -0,0 +1,247 @@
+package workspace
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"sort"
+	"strings"
+
+	"github.com/jedib0t/go-pretty/v6/table"
+	"github.com/salberternst/workspace/pkg/k8s"
+	"github.com/spf13/cobra"
+	"golang.org/x/term"
+	"k8s.io/apimachinery/pkg/util/validation"
+)
+
+// SecretsArgs are the arguments shared by the secrets commands
+type SecretsArgs struct {
+	Name      string
+	Namespace string
+	Key       string
+	// Restart deletes the workspace pod so that the change takes effect
+	Restart bool
+}
+
+func (o *SecretsArgs) Complete(cmd *cobra.Command, args []string, requireKey bool) error {
+	if len(args) == 0 {
+		return errors.New("missing argument: name")
+	}
+
+	if requireKey && len(args) < 2 {
+		return errors.New("missing argument: key")
+	}
+
+	var err error
+
+	o.Name = args[0]
+
+	if requireKey {
+		o.Key = args[1]
+		if errs := validation.IsEnvVarName(o.Key); len(errs) > 0 {
+			return fmt.Errorf("invalid environment variable name %s: %s", o.Key, strings.Join(errs, ", "))
+		}
+	}
+
+	if o.Namespace, err = cmd.Flags().GetString("namespace"); err != nil {
+		return err
+	}
+
+	return nil
+}
+
+// restart applies the secret by recreating the workspace pod, environment
+// variables are only read when the container starts
+func (o *SecretsArgs) restart() error {
+	if !o.Restart {
+		fmt.Printf("Restart the workspace to apply the change, e.g. with --restart\n")
+		return nil
+	}
+
+	workspacePod, err := k8s.GetWorkspacePod(o.Namespace, o.Name)
+	if err != nil {
+		return err
+	}
+
+	if workspacePod == nil {
+		return fmt.Errorf("workspace %s in namespace %s not found", o.Name, o.Namespace)
+	}
+
+	if err := k8s.DeletePod(workspacePod.Namespace, workspacePod.Name); err != nil {
+		return err
+	}
+
+	fmt.Printf("Restarting workspace %s in namespace %s\n", o.Name, o.Namespace)
+	return nil
+}
+
+type SetSecretOptions struct {
+	SecretsArgs
+	FromFile string
+	value    []byte
+}
+
+func (o *SetSecretOptions) Complete(cmd *cobra.Command, args []string) error {
+	if err := o.SecretsArgs.Complete(cmd, args, true); err != nil {
+		return err
+	}
+
+	var err error
+
+	if o.FromFile != "" {
+		o.value, err = os.ReadFile(o.FromFile)
+		return err
+	}
+
+	o.value, err = readSecretValue(o.Key)
+	return err
+}
+
+// readSecretValue prompts for the value without echoing it, so that it ends up
+// neither in the shell history nor in the helm values. If stdin is not a
+// terminal the value is read from stdin.
+func readSecretValue(key string) ([]byte, error) {
+	if term.IsTerminal(int(os.Stdin.Fd())) {
+		fmt.Fprintf(os.Stderr, "Value of %s: ", key)
+		value, err := term.ReadPassword(int(os.Stdin.Fd()))
+		fmt.Fprintln(os.Stderr)
+		return value, err
+	}
+
+	value, err := io.ReadAll(os.Stdin)
+	if err != nil {
+		return nil, err
+	}
+
+	return []byte(strings.TrimSuffix(strings.TrimSuffix(string(value), "\n"), "\r")), nil
+}
+
+func (o *SetSecretOptions) Run() error {
+	if err := k8s.SetWorkspaceSecretValue(o.Name, o.Namespace, o.Key, o.value); err != nil {
+		return err
+	}
+
+	fmt.Printf("Secret %s of workspace %s in namespace %s set\n", o.Key, o.Name, o.Namespace)
+
+	return o.restart()
+}
+
+func NewCmdSetSecret() *cobra.Command {
+	options := SetSecretOptions{}
+
+	var command = &cobra.Command{
+		Use:   "set name KEY",
+		Short: "Set a secret environment variable of a workspace, the value is read from the terminal or stdin",
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if err := options.Complete(cmd, args); err != nil {
+				return err
+			}
+
+			return options.Run()
+		},
+	}
+
+	command.Flags().StringVar(&options.FromFile, "from-file", "", "Read the value from a file")
+	command.Flags().BoolVar(&options.Restart, "restart", false, "Restart the workspace to apply the secret")
+
+	return command
+}
+
+type UnsetSecretOptions struct {
+	SecretsArgs
+}
+
+func (o *UnsetSecretOptions) Run() error {
+	deleted, err := k8s.DeleteWorkspaceSecretValue(o.Name, o.Namespace, o.Key)
+	if err != nil {
+		return err
+	}
+
+	if !deleted {
+		return fmt.Errorf("Secret %s of workspace %s in namespace %s not found", o.Key, o.Name, o.Namespace)
+	}
+
+	fmt.Printf("Secret %s of workspace %s in namespace %s removed\n", o.Key, o.Name, o.Namespace)
+
+	return o.restart()
+}
+
+func NewCmdUnsetSecret() *cobra.Command {
+	options := UnsetSecretOptions{}
+
+	var command = &cobra.Command{
+		Use:   "unset name KEY",
+		Short: "Remove a secret environment variable of a workspace",
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if err := options.Complete(cmd, args, true); err != nil {
+				return err
+			}
+
+			return options.Run()
+		},
+	}
+
+	command.Flags().BoolVar(&options.Restart, "restart", false, "Restart the workspace to apply the change")
+
+	return command
+}
+
+type ListSecretsOptions struct {
+	SecretsArgs
+}
+
+func (o *ListSecretsOptions) Run() error {
+	keys, err := k8s.GetWorkspaceSecretKeys(o.Name, o.Namespace)
+	if err != nil {
+		return err
+	}
+
+	if len(keys) == 0 {
+		fmt.Printf("No secrets found in workspace %s in namespace %s\n", o.Name, o.Namespace)
+		return nil
+	}
+
+	sort.Strings(keys)
+
+	t := table.NewWriter()
+	t.SetOutputMirror(os.Stdout)
+	t.AppendHeader(table.Row{"Key"})
+	for _, key := range keys {
+		t.AppendRow(table.Row{key})
+	}
+	t.Render()
+
+	return nil
+}
+
+func NewCmdListSecrets() *cobra.Command {
+	options := ListSecretsOptions{}
+
+	var command = &cobra.Command{
+		Use:   "list name",
+		Short: "List the keys of the secret environment variables of a workspace",
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if err := options.Complete(cmd, args, false); err != nil {
+				return err
+			}
+
+			return options.Run()
+		},
+	}
+
+	return command
+}
+
+func NewCmdSecrets() *cobra.Command {
+	var command = &cobra.Command{
+		Use:   "secrets",
+		Short: "Manage secret environment variables of a workspace",
+	}
+
+	command.AddCommand(NewCmdSetSecret())
+	command.AddCommand(NewCmdUnsetSecret())
+	command.AddCommand(NewCmdListSecrets())
+
+	return command
+}
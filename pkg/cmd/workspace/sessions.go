@@ -0,0 +1,127 @@
+package workspace
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/jedib0t/go-pretty/v6/table"
+	"github.com/salberternst/workspace/pkg/k8s"
+	"github.com/spf13/cobra"
+	v1 "k8s.io/api/core/v1"
+	"k8s.io/client-go/util/exec"
+)
+
+const sessionListFormat = "#{session_name}\t#{session_windows}\t#{session_created}\t#{session_attached}"
+
+const listSessionsScript = `command -v tmux >/dev/null 2>&1 || exit 127
+exec tmux list-sessions -F "$1"`
+
+type ListSessionsOptions struct {
+	Name         string
+	Namespace    string
+	Container    string
+	workspacePod *v1.Pod
+}
+
+func (o *ListSessionsOptions) Complete(cmd *cobra.Command, args []string) error {
+	if len(args) == 0 {
+		return errors.New("missing argument: name")
+	}
+
+	var err error
+
+	o.Name = args[0]
+
+	if o.Namespace, err = cmd.Flags().GetString("namespace"); err != nil {
+		return err
+	}
+
+	if o.workspacePod, err = k8s.GetWorkspacePod(o.Namespace, o.Name); err != nil {
+		return err
+	}
+
+	if o.workspacePod == nil {
+		return fmt.Errorf("workspace %s in namespace %s not found", o.Name, o.Namespace)
+	}
+
+	return nil
+}
+
+func (o *ListSessionsOptions) Run() error {
+	var stdout, stderr bytes.Buffer
+
+	err := k8s.ExecuteInPod(o.workspacePod.Namespace, o.workspacePod.Name, o.Container, []string{"bash", "-c", listSessionsScript, "workspace-sessions", sessionListFormat}, k8s.ExecStreams{
+		Stdout: &stdout,
+		Stderr: &stderr,
+	})
+
+	var exitError exec.ExitError
+	if errors.As(err, &exitError) {
+		// tmux exits with 1 if no server (and therefore no session) is running
+		if exitError.ExitStatus() == 1 {
+			fmt.Printf("No sessions found in workspace %s in namespace %s\n", o.Name, o.Namespace)
+			return nil
+		}
+
+		if exitError.ExitStatus() == 127 {
+			return fmt.Errorf("tmux is not installed in workspace %s in namespace %s", o.Name, o.Namespace)
+		}
+
+		return fmt.Errorf("failed to list sessions: %s", strings.TrimSpace(stderr.String()))
+	}
+
+	if err != nil {
+		return err
+	}
+
+	printSessions(stdout.String())
+
+	return nil
+}
+
+func printSessions(output string) {
+	t := table.NewWriter()
+	t.SetOutputMirror(os.Stdout)
+	t.AppendHeader(table.Row{"Name", "Windows", "Created At", "Attached Clients"})
+
+	for _, line := range strings.Split(strings.TrimSpace(output), "\n") {
+		fields := strings.Split(line, "\t")
+		if len(fields) != 4 {
+			continue
+		}
+
+		createdAt := fields[2]
+		if seconds, err := strconv.ParseInt(fields[2], 10, 64); err == nil {
+			createdAt = time.Unix(seconds, 0).Local().String()
+		}
+
+		t.AppendRow(table.Row{fields[0], fields[1], createdAt, fields[3]})
+	}
+
+	t.Render()
+}
+
+func NewCmdListSessions() *cobra.Command {
+	options := ListSessionsOptions{}
+
+	var command = &cobra.Command{
+		Use:   "sessions name",
+		Short: "List the persistent terminal sessions of a workspace",
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if err := options.Complete(cmd, args); err != nil {
+				return err
+			}
+
+			return options.Run()
+		},
+	}
+
+	command.Flags().StringVar(&options.Container, "container", WorkspaceContainerName, "The container to list the sessions of")
+
+	return command
+}
@@ -0,0 +1,205 @@
+package workspace
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"os/signal"
+	"syscall"
+
+	"github.com/salberternst/workspace/pkg/k8s"
+	"github.com/salberternst/workspace/pkg/ssh"
+	"github.com/spf13/cobra"
+	"golang.org/x/term"
+	v1 "k8s.io/api/core/v1"
+)
+
+const (
+	SshUsername      = "workspace"
+	SshContainerPort = 2222
+)
+
+type SshOptions struct {
+	Name           string
+	Namespace      string
+	Command        []string
+	Tty            bool
+	NoTty          bool
+	NoCommand      bool
+	LocalForwards  []string
+	RemoteForwards []string
+	// WaitTimeoutInSeconds limits how long to wait for a starting workspace
+	WaitTimeoutInSeconds uint
+	localForwards        []ssh.Forward
+	remoteForwards       []ssh.Forward
+	privateKey           []byte
+	workspacePod         *v1.Pod
+}
+
+func readPrivateKey(name string, namespace string) ([]byte, error) {
+	secret, err := k8s.ReadSecret(name, namespace)
+	if err != nil {
+		return nil, err
+	}
+
+	privateKey, ok := secret.Data[PrivateKeySecretKey]
+	if !ok {
+		return nil, fmt.Errorf("%s does not exists in secret %s in namespace %s", PrivateKeySecretKey, name, namespace)
+	}
+
+	return privateKey, nil
+}
+
+func (o *SshOptions) Complete(cmd *cobra.Command, args []string, argsLengthAtDash int) error {
+	if len(args) == 0 || argsLengthAtDash == 0 {
+		return errors.New("missing argument: name")
+	}
+
+	var err error
+
+	o.Name = args[0]
+
+	if o.Namespace, err = cmd.Flags().GetString("namespace"); err != nil {
+		return err
+	}
+
+	if argsLengthAtDash > 0 {
+		o.Command = args[argsLengthAtDash:]
+	}
+
+	for _, value := range o.LocalForwards {
+		forward, err := ssh.ParseForward(value)
+		if err != nil {
+			return err
+		}
+		o.localForwards = append(o.localForwards, forward)
+	}
+
+	for _, value := range o.RemoteForwards {
+		forward, err := ssh.ParseForward(value)
+		if err != nil {
+			return err
+		}
+		o.remoteForwards = append(o.remoteForwards, forward)
+	}
+
+	if o.workspacePod, err = k8s.GetWorkspacePod(o.Namespace, o.Name); err != nil {
+		return err
+	}
+
+	if o.workspacePod == nil {
+		return fmt.Errorf("workspace %s in namespace %s not found", o.Name, o.Namespace)
+	}
+
+	if o.workspacePod, err = waitForWorkspaceReady(o.workspacePod, o.Name, o.WaitTimeoutInSeconds); err != nil {
+		return err
+	}
+
+	if o.privateKey, err = readPrivateKey(o.Name, o.Namespace); err != nil {
+		return err
+	}
+
+	return nil
+}
+
+func (o *SshOptions) Validate() error {
+	if o.Tty && o.NoTty {
+		return errors.New("--tty and --no-tty can not be used together")
+	}
+
+	if o.NoCommand && len(o.Command) > 0 {
+		return errors.New("--no-command can not be used together with a command")
+	}
+
+	return nil
+}
+
+// allocateTty follows ssh: a pty is requested for interactive shells unless disabled
+func (o *SshOptions) allocateTty() bool {
+	if o.NoTty {
+		return false
+	}
+
+	if o.Tty {
+		return true
+	}
+
+	return len(o.Command) == 0 && term.IsTerminal(int(os.Stdin.Fd()))
+}
+
+func (o *SshOptions) Run() error {
+	conn, err := k8s.GetClient().DialPort(o.workspacePod.Name, o.workspacePod.Namespace, SshContainerPort)
+	if err != nil {
+		return err
+	}
+
+	client, err := ssh.NewClient(conn, SshUsername, o.privateKey)
+	if err != nil {
+		conn.Close()
+		return err
+	}
+
+	defer client.Close()
+
+	for _, forward := range o.localForwards {
+		if err := client.ForwardLocal(forward); err != nil {
+			return err
+		}
+	}
+
+	for _, forward := range o.remoteForwards {
+		if err := client.ForwardRemote(forward); err != nil {
+			return err
+		}
+	}
+
+	if o.NoCommand {
+		signalTermination := make(chan os.Signal, 1)
+		signal.Notify(signalTermination, syscall.SIGINT, syscall.SIGTERM)
+
+		closed := make(chan error, 1)
+		go func() {
+			closed <- client.Wait()
+		}()
+
+		fmt.Println("Press CTRL+C to stop")
+
+		select {
+		case err := <-closed:
+			return err
+		case <-signalTermination:
+			return nil
+		}
+	}
+
+	return client.Run(o.Command, o.allocateTty())
+}
+
+func NewCmdSsh() *cobra.Command {
+	options := SshOptions{}
+
+	var command = &cobra.Command{
+		Use:   "ssh name [-- command]",
+		Short: "Connect to a workspace via ssh",
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if err := options.Complete(cmd, args, cmd.ArgsLenAtDash()); err != nil {
+				return err
+			}
+
+			if err := options.Validate(); err != nil {
+				return err
+			}
+
+			return options.Run()
+		},
+	}
+
+	command.Flags().BoolVar(&options.Tty, "tty", false, "Force pseudo-terminal allocation")
+	command.Flags().BoolVar(&options.NoTty, "no-tty", false, "Disable pseudo-terminal allocation")
+	command.Flags().BoolVar(&options.NoCommand, "no-command", false, "Do not execute a remote command, only forward ports")
+	command.Flags().StringArrayVar(&options.LocalForwards, "local-forward", []string{}, "Forward a local port to the workspace in the form of [bind_address:]port:host:hostport")
+	command.Flags().UintVar(&options.WaitTimeoutInSeconds, "wait-timeout", 300, "Time to wait for a starting workspace to get ready in seconds")
+	command.Flags().StringArrayVar(&options.RemoteForwards, "remote-forward", []string{}, "Forward a port of the workspace to the local machine in the form of [bind_address:]port:host:hostport")
+
+	return command
+}
@@ -0,0 +1,184 @@
+package workspace
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/salberternst/workspace/pkg/synchronization"
+	"github.com/spf13/cobra"
+)
+
+// SyncArgs are the synchronization flags shared by dev and sync start
+type SyncArgs struct {
+	Folders []string
+	Ignores []string
+	Labels  map[string]string
+	Mode    string
+	// SymbolicLinkMode, LocalConfig and RemoteConfig are passed to mutagen
+	SymbolicLinkMode string
+	LocalConfig      map[string]string
+	RemoteConfig     map[string]string
+	// Transport is used to reach the workspace, either ssh or exec
+	Transport string
+	// NoIgnoreFiles disables loading .gitignore and .workspaceignore files
+	NoIgnoreFiles bool
+	// Timeout limits how long to wait for the sessions to connect
+	Timeout time.Duration
+	folders []synchronization.SyncFolder
+}
+
+func (o *SyncArgs) AddFlags(cmd *cobra.Command) {
+	cmd.Flags().StringArrayVar(&o.Ignores, "sync-ignore", []string{".mutagen", ".git"}, "List of folders and files to ignore")
+	cmd.Flags().StringToStringVar(&o.Labels, "sync-label", map[string]string{}, "List of custom labels to add")
+	cmd.Flags().StringVar(&o.Mode, "sync-mode", "two-way-safe", fmt.Sprintf("Set the synchronization mode, one of %s, see https://mutagen.io/documentation/synchronization", strings.Join(synchronization.SyncModes, ", ")))
+	cmd.Flags().StringVar(&o.SymbolicLinkMode, "sync-symlink-mode", "", fmt.Sprintf("Set the symbolic link mode, one of %s (default portable)", strings.Join(synchronization.SymbolicLinkModes, ", ")))
+	cmd.Flags().StringToStringVar(&o.LocalConfig, "sync-local-config", map[string]string{}, fmt.Sprintf("Configure the local endpoint, allowed keys are %s", strings.Join(synchronization.EndpointConfigKeys, ", ")))
+	cmd.Flags().StringToStringVar(&o.RemoteConfig, "sync-remote-config", map[string]string{}, fmt.Sprintf("Configure the endpoint in the workspace, allowed keys are %s, owner and group default to id:1000", strings.Join(synchronization.EndpointConfigKeys, ", ")))
+	cmd.Flags().StringVar(&o.Transport, "sync-transport", synchronization.TransportSsh, fmt.Sprintf("Transport used to synchronize, one of %s", strings.Join(synchronization.Transports, ", ")))
+	cmd.Flags().BoolVar(&o.NoIgnoreFiles, "sync-no-ignore-files", false, "Do not load ignore rules from .gitignore and .workspaceignore files")
+	cmd.Flags().DurationVar(&o.Timeout, "sync-timeout", 30*time.Second, "Time to wait for the sync sessions to connect")
+	cmd.Flags().StringArrayVar(&o.Folders, "sync-folder", []string{}, "Synchronize a folder to the workspace in the form of source:target[,mode=<mode>][,ignore=<pattern>]..., can be repeated")
+}
+
+func (o *SyncArgs) Complete() error {
+	if err := o.validateTransport(); err != nil {
+		return err
+	}
+
+	if err := o.SessionConfig().Validate(); err != nil {
+		return err
+	}
+
+	if o.Timeout <= 0 {
+		return fmt.Errorf("Invalid value %s for --sync-timeout, must be positive", o.Timeout)
+	}
+
+	o.folders = nil
+
+	for _, value := range o.Folders {
+		folder, err := synchronization.ParseSyncFolder(value)
+		if err != nil {
+			return err
+		}
+
+		if !o.NoIgnoreFiles {
+			if err := folder.LoadIgnoreFiles(o.Ignores); err != nil {
+				return err
+			}
+		}
+
+		o.folders = append(o.folders, folder)
+	}
+
+	return nil
+}
+
+func (o *SyncArgs) validateTransport() error {
+	for _, transport := range synchronization.Transports {
+		if o.Transport == transport {
+			return nil
+		}
+	}
+
+	return fmt.Errorf("Invalid value %s for --sync-transport, allowed values are %s", o.Transport, strings.Join(synchronization.Transports, ", "))
+}
+
+// defaultRemoteConfig lets files created in the workspace belong to the
+// workspace user, keys set with --sync-remote-config take precedence
+var defaultRemoteConfig = map[string]string{"owner": "id:1000", "group": "id:1000"}
+
+// SessionConfig returns the configuration of the sync sessions
+func (o *SyncArgs) SessionConfig() synchronization.SessionConfig {
+	remoteConfig := map[string]string{}
+	for key, value := range defaultRemoteConfig {
+		remoteConfig[key] = value
+	}
+	for key, value := range o.RemoteConfig {
+		remoteConfig[key] = value
+	}
+
+	return synchronization.SessionConfig{
+		Mode:             o.Mode,
+		SymbolicLinkMode: o.SymbolicLinkMode,
+		Local:            o.LocalConfig,
+		Remote:           remoteConfig,
+	}
+}
+
+// buildTarget returns the target of the sync sessions, the port is the local
+// port forwarded to ssh and only used by the ssh transport
+func (o *SyncArgs) buildTarget(name string, namespace string, kubeConfigPath string, port uint16) synchronization.Target {
+	return synchronization.Target{
+		Transport:      o.Transport,
+		Port:           port,
+		Hostname:       fmt.Sprintf("%s.%s.workspace", name, namespace),
+		Username:       SshUsername,
+		Name:           name,
+		Namespace:      namespace,
+		Container:      WorkspaceContainerName,
+		KubeConfigPath: kubeConfigPath,
+	}
+}
+
+// Args serializes the flags again, e.g. to pass them to the sync daemon
+func (o *SyncArgs) Args() []string {
+	args := []string{"--sync-mode", o.Mode, "--sync-transport", o.Transport, "--sync-timeout", o.Timeout.String()}
+
+	for _, ignore := range o.Ignores {
+		args = append(args, "--sync-ignore", ignore)
+	}
+
+	if o.SymbolicLinkMode != "" {
+		args = append(args, "--sync-symlink-mode", o.SymbolicLinkMode)
+	}
+
+	args = appendMapArgs(args, "--sync-label", o.Labels)
+	args = appendMapArgs(args, "--sync-local-config", o.LocalConfig)
+	args = appendMapArgs(args, "--sync-remote-config", o.RemoteConfig)
+
+	if o.NoIgnoreFiles {
+		args = append(args, "--sync-no-ignore-files")
+	}
+
+	for _, folder := range o.Folders {
+		args = append(args, "--sync-folder", folder)
+	}
+
+	return args
+}
+
+// appendMapArgs appends a key=value flag per entry, sorted by key
+func appendMapArgs(args []string, flag string, values map[string]string) []string {
+	keys := make([]string, 0, len(values))
+	for key := range values {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	for _, key := range keys {
+		args = append(args, flag, key+"="+values[key])
+	}
+
+	return args
+}
+
+func NewCmdSync() *cobra.Command {
+	var command = &cobra.Command{
+		Use:   "sync",
+		Short: "Synchronize folders to a workspace in the background",
+	}
+
+	command.AddCommand(NewCmdSyncStart())
+	command.AddCommand(NewCmdSyncDaemon())
+	command.AddCommand(NewCmdSyncStatus())
+	command.AddCommand(NewCmdSyncConflicts())
+	command.AddCommand(NewCmdSyncResolve())
+	command.AddCommand(NewCmdSyncIgnored())
+	command.AddCommand(NewCmdSyncPause())
+	command.AddCommand(NewCmdSyncResume())
+	command.AddCommand(NewCmdSyncStop())
+
+	return command
+}
@@ -0,0 +1,289 @@
+package workspace
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/jedib0t/go-pretty/v6/table"
+	"github.com/salberternst/workspace/pkg/k8s"
+	"github.com/salberternst/workspace/pkg/synchronization"
+	"github.com/spf13/cobra"
+)
+
+// removeRemoteScript removes a path, relative paths are relative to the home
+// folder like with mutagen, see synchronization.Conflict.RemotePath
+const removeRemoteScript = `cd && rm -rf -- "$1"`
+
+func conflictPath(conflict synchronization.Conflict) string {
+	if conflict.Path == "" {
+		return "."
+	}
+	return conflict.Path
+}
+
+type SyncConflictsOptions struct {
+	Name      string
+	Namespace string
+}
+
+func (o *SyncConflictsOptions) Complete(cmd *cobra.Command, args []string) error {
+	if len(args) == 0 {
+		return errors.New("missing argument: name")
+	}
+
+	var err error
+
+	o.Name = args[0]
+
+	if o.Namespace, err = cmd.Flags().GetString("namespace"); err != nil {
+		return err
+	}
+
+	return nil
+}
+
+func (o *SyncConflictsOptions) Run() error {
+	client, err := dialSync(o.Name, o.Namespace)
+	if err != nil {
+		return err
+	}
+	defer client.Close()
+
+	conflicts, err := client.Conflicts()
+	if err != nil {
+		return err
+	}
+
+	if len(conflicts) == 0 {
+		fmt.Printf("No conflicts in workspace %s in namespace %s\n", o.Name, o.Namespace)
+		return nil
+	}
+
+	t := table.NewWriter()
+	t.SetOutputMirror(os.Stdout)
+	t.AppendHeader(table.Row{"Folder", "Path", "Local Changes (alpha)", "Remote Changes (beta)"})
+
+	for _, conflict := range conflicts {
+		t.AppendRow(table.Row{
+			conflict.Source + ":" + conflict.Target,
+			conflictPath(conflict),
+			strings.Join(conflict.LocalChanges, "\n"),
+			strings.Join(conflict.RemoteChanges, "\n"),
+		})
+	}
+
+	t.Render()
+
+	return nil
+}
+
+func NewCmdSyncConflicts() *cobra.Command {
+	options := SyncConflictsOptions{}
+
+	var command = &cobra.Command{
+		Use:   "conflicts name",
+		Short: "List the conflicting changes of the synchronization of a workspace",
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if err := options.Complete(cmd, args); err != nil {
+				return err
+			}
+
+			return options.Run()
+		},
+	}
+
+	return command
+}
+
+type SyncResolveOptions struct {
+	Name      string
+	Namespace string
+	Paths     []string
+	Keep      string
+	Force     bool
+}
+
+func (o *SyncResolveOptions) Complete(cmd *cobra.Command, args []string) error {
+	if len(args) == 0 {
+		return errors.New("missing argument: name")
+	}
+
+	var err error
+
+	o.Name = args[0]
+	o.Paths = args[1:]
+
+	if o.Namespace, err = cmd.Flags().GetString("namespace"); err != nil {
+		return err
+	}
+
+	return nil
+}
+
+func (o *SyncResolveOptions) Validate() error {
+	if o.Keep == "" {
+		return nil
+	}
+
+	for _, resolution := range synchronization.Resolutions {
+		if o.Keep == resolution {
+			return nil
+		}
+	}
+
+	return fmt.Errorf("Invalid value %s for --keep, allowed values are %s", o.Keep, strings.Join(synchronization.Resolutions, ", "))
+}
+
+// selectConflicts returns the conflicts matching the given paths or all if none were given
+func (o *SyncResolveOptions) selectConflicts(conflicts []synchronization.Conflict) []synchronization.Conflict {
+	if len(o.Paths) == 0 {
+		return conflicts
+	}
+
+	var selected []synchronization.Conflict
+	for _, conflict := range conflicts {
+		for _, path := range o.Paths {
+			if conflictPath(conflict) == strings.TrimSuffix(path, "/") {
+				selected = append(selected, conflict)
+			}
+		}
+	}
+	return selected
+}
+
+// promptResolution asks which side to keep, an empty resolution skips the conflict
+func promptResolution(reader *bufio.Reader, conflict synchronization.Conflict) (string, error) {
+	fmt.Printf("\nConflict at %s in %s:%s\n", conflictPath(conflict), conflict.Source, conflict.Target)
+	fmt.Printf("  local:  %s\n", strings.Join(conflict.LocalChanges, ", "))
+	fmt.Printf("  remote: %s\n", strings.Join(conflict.RemoteChanges, ", "))
+
+	for {
+		fmt.Print("Keep [l]ocal, [r]emote, [b]oth or [s]kip? ")
+
+		answer, err := reader.ReadString('\n')
+		if err != nil {
+			return "", err
+		}
+
+		switch strings.ToLower(strings.TrimSpace(answer)) {
+		case "l", "local":
+			return synchronization.ResolutionLocal, nil
+		case "r", "remote":
+			return synchronization.ResolutionRemote, nil
+		case "b", "both":
+			return synchronization.ResolutionKeepBoth, nil
+		case "s", "skip":
+			return "", nil
+		}
+	}
+}
+
+// resolve removes the side which should not be kept, mutagen then propagates
+// the remaining side. For keep-both the local copy is moved aside first.
+func (o *SyncResolveOptions) resolve(conflict synchronization.Conflict, resolution string) error {
+	switch resolution {
+	case synchronization.ResolutionLocal:
+		workspacePod, err := k8s.GetWorkspacePod(o.Namespace, o.Name)
+		if err != nil {
+			return err
+		}
+
+		if workspacePod == nil {
+			return fmt.Errorf("workspace %s in namespace %s not found", o.Name, o.Namespace)
+		}
+
+		return k8s.ExecuteInPod(workspacePod.Namespace, workspacePod.Name, WorkspaceContainerName, []string{"bash", "-c", removeRemoteScript, "workspace-resolve", conflict.RemotePath()}, k8s.ExecStreams{
+			Stdout: os.Stdout,
+			Stderr: os.Stderr,
+		})
+	case synchronization.ResolutionRemote:
+		return os.RemoveAll(conflict.LocalPath())
+	case synchronization.ResolutionKeepBoth:
+		return os.Rename(conflict.LocalPath(), fmt.Sprintf("%s.conflict-%s", conflict.LocalPath(), time.Now().Format("20060102150405")))
+	}
+
+	return nil
+}
+
+func (o *SyncResolveOptions) Run() error {
+	client, err := dialSync(o.Name, o.Namespace)
+	if err != nil {
+		return err
+	}
+	defer client.Close()
+
+	conflicts, err := client.Conflicts()
+	if err != nil {
+		return err
+	}
+
+	conflicts = o.selectConflicts(conflicts)
+	if len(conflicts) == 0 {
+		fmt.Printf("No matching conflicts in workspace %s in namespace %s\n", o.Name, o.Namespace)
+		return nil
+	}
+
+	reader := bufio.NewReader(os.Stdin)
+	resolved := 0
+
+	for _, conflict := range conflicts {
+		// resolving a conflict at the root removes or moves the whole folder
+		if conflict.Path == "" && !o.Force {
+			fmt.Printf("Skipping conflict at the root of %s:%s, resolving it replaces the whole folder, use --force to resolve it\n", conflict.Source, conflict.Target)
+			continue
+		}
+
+		resolution := o.Keep
+		if resolution == "" {
+			if resolution, err = promptResolution(reader, conflict); err != nil {
+				return err
+			}
+		}
+
+		if resolution == "" {
+			continue
+		}
+
+		if err := o.resolve(conflict, resolution); err != nil {
+			return fmt.Errorf("Failed to resolve conflict at %s: %w", conflictPath(conflict), err)
+		}
+
+		fmt.Printf("Resolved conflict at %s by keeping %s\n", conflictPath(conflict), resolution)
+		resolved++
+	}
+
+	if resolved == 0 {
+		return nil
+	}
+
+	return client.Flush()
+}
+
+func NewCmdSyncResolve() *cobra.Command {
+	options := SyncResolveOptions{}
+
+	var command = &cobra.Command{
+		Use:   "resolve name [path...]",
+		Short: "Resolve conflicts of the synchronization of a workspace",
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if err := options.Complete(cmd, args); err != nil {
+				return err
+			}
+
+			if err := options.Validate(); err != nil {
+				return err
+			}
+
+			return options.Run()
+		},
+	}
+
+	command.Flags().StringVar(&options.Keep, "keep", "", fmt.Sprintf("Resolve without asking by keeping one of %s", strings.Join(synchronization.Resolutions, ", ")))
+	command.Flags().BoolVar(&options.Force, "force", false, "Also resolve conflicts at the root of a folder, which removes or moves the whole local or remote folder")
+
+	return command
+}
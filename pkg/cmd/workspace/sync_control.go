@@ -0,0 +1,82 @@
+package workspace
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/salberternst/workspace/pkg/synchronization"
+	"github.com/spf13/cobra"
+)
+
+// SyncControlOptions sends a single request to the running synchronization
+type SyncControlOptions struct {
+	Name      string
+	Namespace string
+	action    func(client *synchronization.ControlClient) error
+	message   string
+}
+
+func (o *SyncControlOptions) Complete(cmd *cobra.Command, args []string) error {
+	if len(args) == 0 {
+		return errors.New("missing argument: name")
+	}
+
+	var err error
+
+	o.Name = args[0]
+
+	if o.Namespace, err = cmd.Flags().GetString("namespace"); err != nil {
+		return err
+	}
+
+	return nil
+}
+
+func (o *SyncControlOptions) Run() error {
+	client, err := dialSync(o.Name, o.Namespace)
+	if err != nil {
+		return err
+	}
+	defer client.Close()
+
+	if err := o.action(client); err != nil {
+		return err
+	}
+
+	fmt.Printf(o.message+"\n", o.Name, o.Namespace)
+
+	return nil
+}
+
+func newCmdSyncControl(use string, short string, message string, action func(client *synchronization.ControlClient) error) *cobra.Command {
+	options := SyncControlOptions{
+		action:  action,
+		message: message,
+	}
+
+	var command = &cobra.Command{
+		Use:   use,
+		Short: short,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if err := options.Complete(cmd, args); err != nil {
+				return err
+			}
+
+			return options.Run()
+		},
+	}
+
+	return command
+}
+
+func NewCmdSyncPause() *cobra.Command {
+	return newCmdSyncControl("pause name", "Pause the synchronization of a workspace", "Synchronization of workspace %s in namespace %s paused", (*synchronization.ControlClient).Pause)
+}
+
+func NewCmdSyncResume() *cobra.Command {
+	return newCmdSyncControl("resume name", "Resume the synchronization of a workspace", "Synchronization of workspace %s in namespace %s resumed", (*synchronization.ControlClient).Resume)
+}
+
+func NewCmdSyncStop() *cobra.Command {
+	return newCmdSyncControl("stop name", "Stop the synchronization of a workspace", "Synchronization of workspace %s in namespace %s stopped", (*synchronization.ControlClient).Stop)
+}
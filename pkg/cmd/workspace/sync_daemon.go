@@ -0,0 +1,139 @@
+package workspace
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"os/signal"
+	"syscall"
+
+	"github.com/salberternst/workspace/pkg/k8s"
+	"github.com/salberternst/workspace/pkg/synchronization"
+	"github.com/spf13/cobra"
+	v1 "k8s.io/api/core/v1"
+)
+
+// SyncDaemonOptions runs the sync sessions of a workspace until they are stopped
+// via sync stop. It is started in the background by sync start.
+type SyncDaemonOptions struct {
+	Name           string
+	Namespace      string
+	KubeConfigPath string
+	SyncArgs       SyncArgs
+	workspacePod   *v1.Pod
+}
+
+func (o *SyncDaemonOptions) Complete(cmd *cobra.Command, args []string) error {
+	if len(args) == 0 {
+		return errors.New("missing argument: name")
+	}
+
+	var err error
+
+	o.Name = args[0]
+
+	if o.Namespace, err = cmd.Flags().GetString("namespace"); err != nil {
+		return err
+	}
+
+	if o.KubeConfigPath, err = cmd.Flags().GetString("kube-config"); err != nil {
+		return err
+	}
+
+	if err := o.SyncArgs.Complete(); err != nil {
+		return err
+	}
+
+	if o.workspacePod, err = k8s.GetWorkspacePod(o.Namespace, o.Name); err != nil {
+		return err
+	}
+
+	if o.workspacePod == nil {
+		return fmt.Errorf("workspace %s in namespace %s not found", o.Name, o.Namespace)
+	}
+
+	return nil
+}
+
+// forwardSsh forwards a random local port to ssh for the ssh transport
+func (o *SyncDaemonOptions) forwardSsh() (k8s.PortForward, error) {
+	if err := setupSshConfig(o.Name, o.Namespace); err != nil {
+		return k8s.PortForward{}, err
+	}
+
+	// a random local port avoids conflicts with dev sessions of the same workspace
+	return k8s.GetClient().ForwardPorts(o.workspacePod.Name, o.workspacePod.Namespace, []string{fmt.Sprintf(":%d", SshContainerPort)})
+}
+
+func (o *SyncDaemonOptions) Run() error {
+	server, err := synchronization.Listen(o.Name, o.Namespace)
+	if err != nil {
+		return err
+	}
+	defer server.Close()
+
+	var port uint16
+	var portForwardStopped chan struct{}
+
+	if o.SyncArgs.Transport == synchronization.TransportSsh {
+		portForward, err := o.forwardSsh()
+		if err != nil {
+			return err
+		}
+
+		port = portForward.ForwardedPorts[0].Local
+		portForwardStopped = portForward.StopChannel
+	}
+
+	fileManager, err := synchronization.NewFileManager(o.Name, o.Namespace, synchronization.OutputQuiet)
+	if err != nil {
+		return err
+	}
+	defer fileManager.Stop()
+
+	if err := server.Serve(fileManager); err != nil {
+		return err
+	}
+
+	target := o.SyncArgs.buildTarget(o.Name, o.Namespace, o.KubeConfigPath, port)
+
+	if err := fileManager.Run(o.SyncArgs.folders, target, o.SyncArgs.Ignores, o.SyncArgs.Labels, true, o.SyncArgs.SessionConfig(), o.SyncArgs.Timeout); err != nil {
+		return err
+	}
+
+	fmt.Printf("Synchronizing %d folders to workspace %s in namespace %s\n", len(o.SyncArgs.folders), o.Name, o.Namespace)
+
+	signalTermination := make(chan os.Signal, 1)
+	signal.Notify(signalTermination, syscall.SIGINT, syscall.SIGTERM)
+
+	select {
+	case <-server.Done():
+		fmt.Println("Synchronization stopped")
+		return nil
+	case <-signalTermination:
+		fmt.Println("Synchronization terminated")
+		return nil
+	case <-portForwardStopped:
+		return errors.New("Port forward to the workspace was closed")
+	}
+}
+
+func NewCmdSyncDaemon() *cobra.Command {
+	options := SyncDaemonOptions{}
+
+	var command = &cobra.Command{
+		Use:    "daemon name",
+		Hidden: true,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if err := options.Complete(cmd, args); err != nil {
+				return err
+			}
+
+			return options.Run()
+		},
+	}
+
+	options.SyncArgs.AddFlags(command)
+
+	return command
+}
@@ -0,0 +1,84 @@
+package workspace
+
+import (
+	"fmt"
+	"path/filepath"
+	"strings"
+
+	"github.com/mutagen-io/mutagen/pkg/filesystem"
+	"github.com/salberternst/workspace/pkg/synchronization"
+	"github.com/spf13/cobra"
+)
+
+// SyncIgnoredOptions previews the paths excluded from the synchronization
+type SyncIgnoredOptions struct {
+	SyncArgs SyncArgs
+	folders  []synchronization.SyncFolder
+}
+
+func (o *SyncIgnoredOptions) Complete(cmd *cobra.Command, args []string) error {
+	if err := o.SyncArgs.Complete(); err != nil {
+		return err
+	}
+
+	o.folders = o.SyncArgs.folders
+
+	// without folders the current directory is previewed
+	if len(o.folders) == 0 {
+		folder := synchronization.SyncFolder{Source: "."}
+
+		if !o.SyncArgs.NoIgnoreFiles {
+			if err := folder.LoadIgnoreFiles(o.SyncArgs.Ignores); err != nil {
+				return err
+			}
+		}
+
+		o.folders = []synchronization.SyncFolder{folder}
+	}
+
+	return nil
+}
+
+func (o *SyncIgnoredOptions) Run() error {
+	for _, folder := range o.folders {
+		source, err := filesystem.Normalize(folder.Source)
+		if err != nil {
+			return err
+		}
+
+		ignored, err := synchronization.ListIgnored(source, folder.BuildIgnores(o.SyncArgs.Ignores))
+		if err != nil {
+			return err
+		}
+
+		for _, path := range ignored {
+			name := filepath.Join(folder.Source, filepath.FromSlash(path))
+			if strings.HasSuffix(path, "/") {
+				name += string(filepath.Separator)
+			}
+			fmt.Println(name)
+		}
+	}
+
+	return nil
+}
+
+func NewCmdSyncIgnored() *cobra.Command {
+	options := SyncIgnoredOptions{}
+
+	var command = &cobra.Command{
+		Use:   "ignored",
+		Short: "List the local paths which are excluded from the synchronization",
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if err := options.Complete(cmd, args); err != nil {
+				return err
+			}
+
+			return options.Run()
+		},
+	}
+
+	options.SyncArgs.AddFlags(command)
+
+	return command
+}
@@ -0,0 +1,139 @@
+package workspace
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"strings"
+	"time"
+
+	"github.com/salberternst/workspace/pkg/synchronization"
+	"github.com/salberternst/workspace/pkg/utils"
+	"github.com/spf13/cobra"
+)
+
+type SyncStartOptions struct {
+	Name           string
+	Namespace      string
+	KubeConfigPath string
+	SyncArgs       SyncArgs
+}
+
+func (o *SyncStartOptions) Complete(cmd *cobra.Command, args []string) error {
+	if len(args) == 0 {
+		return errors.New("missing argument: name")
+	}
+
+	var err error
+
+	o.Name = args[0]
+
+	if o.Namespace, err = cmd.Flags().GetString("namespace"); err != nil {
+		return err
+	}
+
+	if o.KubeConfigPath, err = cmd.Flags().GetString("kube-config"); err != nil {
+		return err
+	}
+
+	return o.SyncArgs.Complete()
+}
+
+func (o *SyncStartOptions) Validate() error {
+	if len(o.SyncArgs.folders) == 0 {
+		return errors.New("at least one --sync-folder is required")
+	}
+
+	return nil
+}
+
+func (o *SyncStartOptions) buildDaemonArgs() []string {
+	args := []string{"sync", "daemon", o.Name, "--namespace", o.Namespace}
+
+	if o.KubeConfigPath != "" {
+		args = append(args, "--kube-config", o.KubeConfigPath)
+	}
+
+	return append(args, o.SyncArgs.Args()...)
+}
+
+func (o *SyncStartOptions) Run() error {
+	if client, err := synchronization.Dial(o.Name, o.Namespace); err == nil {
+		client.Close()
+		return fmt.Errorf("Synchronization of workspace %s in namespace %s is already running", o.Name, o.Namespace)
+	}
+
+	executable, err := os.Executable()
+	if err != nil {
+		return err
+	}
+
+	logPath, err := synchronization.GetLogPath(o.Name, o.Namespace)
+	if err != nil {
+		return err
+	}
+
+	logFile, err := os.OpenFile(logPath, os.O_CREATE|os.O_WRONLY|os.O_TRUNC, 0600)
+	if err != nil {
+		return err
+	}
+	defer logFile.Close()
+
+	daemon := exec.Command(executable, o.buildDaemonArgs()...)
+	daemon.Stdout = logFile
+	daemon.Stderr = logFile
+
+	if err := utils.StartDetached(daemon); err != nil {
+		return err
+	}
+
+	exited := make(chan struct{})
+	go func() {
+		daemon.Wait()
+		close(exited)
+	}()
+
+	deadline := time.After(o.SyncArgs.Timeout)
+
+	for {
+		if client, err := synchronization.Dial(o.Name, o.Namespace); err == nil {
+			client.Close()
+			fmt.Printf("Synchronization of workspace %s in namespace %s started, logs are written to %s\n", o.Name, o.Namespace, logPath)
+			return nil
+		}
+
+		select {
+		case <-exited:
+			output, _ := os.ReadFile(logPath)
+			return fmt.Errorf("Synchronization exited: %s", strings.TrimSpace(string(output)))
+		case <-deadline:
+			return fmt.Errorf("Synchronization did not start within %s, see %s", o.SyncArgs.Timeout, logPath)
+		case <-time.After(200 * time.Millisecond):
+		}
+	}
+}
+
+func NewCmdSyncStart() *cobra.Command {
+	options := SyncStartOptions{}
+
+	var command = &cobra.Command{
+		Use:   "start name",
+		Short: "Start synchronizing folders to the workspace in the background",
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if err := options.Complete(cmd, args); err != nil {
+				return err
+			}
+
+			if err := options.Validate(); err != nil {
+				return err
+			}
+
+			return options.Run()
+		},
+	}
+
+	options.SyncArgs.AddFlags(command)
+
+	return command
+}
@@ -0,0 +1,101 @@
+package workspace
+
+import (
+	"errors"
+	"fmt"
+	"os"
+
+	"github.com/dustin/go-humanize"
+	"github.com/jedib0t/go-pretty/v6/table"
+	"github.com/salberternst/workspace/pkg/synchronization"
+	"github.com/spf13/cobra"
+)
+
+// dialSync connects to the synchronization of the workspace running in dev or sync start
+func dialSync(name string, namespace string) (*synchronization.ControlClient, error) {
+	client, err := synchronization.Dial(name, namespace)
+	if err != nil {
+		return nil, fmt.Errorf("Synchronization of workspace %s in namespace %s is not running", name, namespace)
+	}
+
+	return client, nil
+}
+
+type SyncStatusOptions struct {
+	Name      string
+	Namespace string
+}
+
+func (o *SyncStatusOptions) Complete(cmd *cobra.Command, args []string) error {
+	if len(args) == 0 {
+		return errors.New("missing argument: name")
+	}
+
+	var err error
+
+	o.Name = args[0]
+
+	if o.Namespace, err = cmd.Flags().GetString("namespace"); err != nil {
+		return err
+	}
+
+	return nil
+}
+
+func (o *SyncStatusOptions) Run() error {
+	client, err := dialSync(o.Name, o.Namespace)
+	if err != nil {
+		return err
+	}
+	defer client.Close()
+
+	statuses, err := client.Status()
+	if err != nil {
+		return err
+	}
+
+	t := table.NewWriter()
+	t.SetOutputMirror(os.Stdout)
+	t.AppendHeader(table.Row{"Source", "Target", "Status", "Files", "Size", "Staged", "Transferred", "Conflicts", "Last Error"})
+
+	for _, status := range statuses {
+		state := status.Status
+		if status.Paused {
+			state = "Paused"
+		}
+
+		t.AppendRow(table.Row{
+			status.Source,
+			status.Target,
+			state,
+			humanize.Comma(int64(status.Files)),
+			humanize.Bytes(status.TotalFileSize),
+			humanize.Bytes(status.StagedBytes),
+			humanize.Bytes(status.TransferredBytes),
+			status.Conflicts,
+			status.LastError,
+		})
+	}
+
+	t.Render()
+
+	return nil
+}
+
+func NewCmdSyncStatus() *cobra.Command {
+	options := SyncStatusOptions{}
+
+	var command = &cobra.Command{
+		Use:   "status name",
+		Short: "Show the status of the synchronization of a workspace",
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if err := options.Complete(cmd, args); err != nil {
+				return err
+			}
+
+			return options.Run()
+		},
+	}
+
+	return command
+}
@@ -40,24 +40,32 @@ func (o *UpdateWorkspaceOptions) Complete(cmd *cobra.Command, args []string) err
 		return err
 	}
 
-	return nil
+	return o.args.Complete()
 }
 
 func (o *UpdateWorkspaceOptions) Validate() error {
+	if err := o.args.Validate(); err != nil {
+		return err
+	}
+
 	return o.workspaceChart.Get(o.Namespace, o.Name)
 }
 
 func (o *UpdateWorkspaceOptions) Run(cmd *cobra.Command) error {
-	if _, err := o.workspaceChart.Update(o.Namespace, o.Name, false, o.args.BuildValues(cmd)); err != nil {
+	if _, err := o.workspaceChart.Update(o.Namespace, o.Name, false, o.args.BuildValues(cmd), o.args.ReplacedPaths()...); err != nil {
 		return err
 	}
 
 	if !o.NoWait {
-		fmt.Printf("Waiting for workspace %s in namespace %s to become ready\n", o.Name, o.Namespace)
+		// the previous pod keeps running until it is replaced, the messages
+		// refer to the pod of the updated revision
+		fmt.Printf("Waiting for the updated workspace %s in namespace %s to become ready\n", o.Name, o.Namespace)
 		if err := k8s.WaitForStatefulSetReplica(o.Name, o.Namespace, o.WaitTimeoutInSeconds); err != nil {
 			return err
 		}
 
+		fmt.Printf("Workspace %s in namespace %s starting\n", o.Name, o.Namespace)
+
 		var watcher watch.Interface
 		var err error
 
@@ -70,11 +78,11 @@ func (o *UpdateWorkspaceOptions) Run(cmd *cobra.Command) error {
 			defer watcher.Stop()
 		}
 
-		if err := k8s.WaitForStatefulSetReplicaReady(o.Name, o.Namespace, o.WaitTimeoutInSeconds); err != nil {
+		if err := k8s.WaitForWorkspaceReady(o.Name, o.Namespace, o.WaitTimeoutInSeconds); err != nil {
 			return err
 		}
 
-		fmt.Printf("Workspace %s in namespace %s running\n", o.Name, o.Namespace)
+		fmt.Printf("Workspace %s in namespace %s ready\n", o.Name, o.Namespace)
 		fmt.Printf("Use: workspace dev %s --namespace %s\n", o.Name, o.Namespace)
 	}
 
@@ -87,7 +95,8 @@ func NewCmdUpdateWorkspace() *cobra.Command {
 	}
 
 	var command = &cobra.Command{
-		Use: "update name",
+		Use:  "update name",
+		Long: "Update the configuration of a workspace. The volumes are configured on create, their size is changed with workspace volume resize.",
 		Args: func(cmd *cobra.Command, args []string) error {
 			if len(args) < 1 {
 				return errors.New("a name is required")
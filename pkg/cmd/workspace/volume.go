@@ -0,0 +1,188 @@
+package workspace
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/salberternst/workspace/pkg/helm"
+	"github.com/salberternst/workspace/pkg/k8s"
+	"github.com/spf13/cobra"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+)
+
+// volumeResize is a volume of the workspace which is expanded
+type volumeResize struct {
+	claimName string
+	// valuesPath is the path of the volume in the chart values
+	valuesPath string
+	size       resource.Quantity
+}
+
+type ResizeVolumeOptions struct {
+	Name                 string
+	Namespace            string
+	HomeSize             string
+	CondaEnvSize         string
+	WaitTimeoutInSeconds uint
+	workspaceChart       helm.Chart
+	volumes              []volumeResize
+}
+
+func (o *ResizeVolumeOptions) Complete(cmd *cobra.Command, args []string) error {
+	if len(args) < 1 {
+		return errors.New("missing argument: name")
+	}
+
+	var err error
+
+	o.Name = args[0]
+
+	if o.Namespace, err = cmd.Flags().GetString("namespace"); err != nil {
+		return err
+	}
+
+	if o.workspaceChart, err = helm.NewChart("workspace"); err != nil {
+		return err
+	}
+
+	o.volumes = nil
+
+	if o.HomeSize != "" {
+		if err := o.addVolume(o.Name+"-home", "homeVolume", o.HomeSize); err != nil {
+			return err
+		}
+	}
+
+	if o.CondaEnvSize != "" {
+		if err := o.addVolume(o.Name+"-conda-env", "condaEnvVolume", o.CondaEnvSize); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func (o *ResizeVolumeOptions) addVolume(claimName string, valuesPath string, size string) error {
+	quantity, err := resource.ParseQuantity(size)
+	if err != nil {
+		return fmt.Errorf("Invalid volume size %s: %w", size, err)
+	}
+
+	o.volumes = append(o.volumes, volumeResize{
+		claimName:  claimName,
+		valuesPath: valuesPath,
+		size:       quantity,
+	})
+
+	return nil
+}
+
+func (o *ResizeVolumeOptions) Validate() error {
+	if len(o.volumes) == 0 {
+		return errors.New("Nothing to resize, use --home or --conda")
+	}
+
+	if err := o.workspaceChart.Get(o.Namespace, o.Name); err != nil {
+		return err
+	}
+
+	for _, volume := range o.volumes {
+		if err := validateVolumeResize(volume, o.Namespace); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// validateVolumeResize checks that the volume grows and that its storage
+// class supports expansion
+func validateVolumeResize(volume volumeResize, namespace string) error {
+	claim, err := k8s.GetPersistentVolumeClaim(volume.claimName, namespace)
+	if err != nil {
+		return err
+	}
+
+	if size, found := claim.Spec.Resources.Requests[corev1.ResourceStorage]; found && volume.size.Cmp(size) <= 0 {
+		return fmt.Errorf("Volume %s can only grow, the current size is %s", volume.claimName, size.String())
+	}
+
+	if claim.Spec.StorageClassName == nil || *claim.Spec.StorageClassName == "" {
+		return fmt.Errorf("Volume %s has no storage class, the volume can not be expanded", volume.claimName)
+	}
+
+	storageClass, err := k8s.GetStorageClass(*claim.Spec.StorageClassName)
+	if err != nil {
+		return err
+	}
+
+	if storageClass.AllowVolumeExpansion == nil || !*storageClass.AllowVolumeExpansion {
+		return fmt.Errorf("Storage class %s of volume %s does not allow volume expansion", storageClass.Name, volume.claimName)
+	}
+
+	return nil
+}
+
+func (o *ResizeVolumeOptions) Run() error {
+	// the size is changed through the release, otherwise the next update
+	// would shrink the volume to the size of the chart values again
+	values := map[string]interface{}{}
+	for _, volume := range o.volumes {
+		values[volume.valuesPath] = map[string]interface{}{
+			"size": volume.size.String(),
+		}
+	}
+
+	if _, err := o.workspaceChart.Update(o.Namespace, o.Name, false, values); err != nil {
+		return err
+	}
+
+	for _, volume := range o.volumes {
+		fmt.Printf("Waiting for volume %s to be resized to %s\n", volume.claimName, volume.size.String())
+		if err := k8s.WaitForVolumeResize(volume.claimName, o.Namespace, volume.size, o.WaitTimeoutInSeconds); err != nil {
+			return err
+		}
+	}
+
+	fmt.Printf("Successfully resized the volumes of workspace %s in namespace %s\n", o.Name, o.Namespace)
+
+	return nil
+}
+
+func NewCmdResizeVolume() *cobra.Command {
+	options := ResizeVolumeOptions{}
+
+	var command = &cobra.Command{
+		Use:   "resize name",
+		Short: "Expand the volumes of a workspace",
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if err := options.Complete(cmd, args); err != nil {
+				return err
+			}
+
+			if err := options.Validate(); err != nil {
+				return err
+			}
+
+			return options.Run()
+		},
+	}
+
+	command.Flags().StringVar(&options.HomeSize, "home", "", "New size of the home volume (e.g. 100Gi)")
+	command.Flags().StringVar(&options.CondaEnvSize, "conda", "", "New size of the conda environment volume (e.g. 50Gi)")
+	command.Flags().UintVar(&options.WaitTimeoutInSeconds, "wait-timeout", 300, "Time to wait for the file system resize in seconds")
+
+	return command
+}
+
+func NewCmdVolume() *cobra.Command {
+	var command = &cobra.Command{
+		Use:   "volume",
+		Short: "Manage the volumes of a workspace",
+	}
+
+	command.AddCommand(NewCmdResizeVolume())
+
+	return command
+}
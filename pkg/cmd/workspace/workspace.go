@@ -12,5 +12,16 @@ func AddWorkspaceCommands(command *cobra.Command) *cobra.Command {
 	command.AddCommand(NewCmdDeleteWorkspace())
 	command.AddCommand(NewCmdListWorkspaces())
 	command.AddCommand(NewCmdDev())
+	command.AddCommand(NewCmdSsh())
+	command.AddCommand(NewCmdAttach())
+	command.AddCommand(NewCmdListSessions())
+	command.AddCommand(NewCmdSync())
+	command.AddCommand(NewCmdPush())
+	command.AddCommand(NewCmdPull())
+	command.AddCommand(NewCmdSecrets())
+	command.AddCommand(NewCmdVolume())
+	command.AddCommand(NewCmdRegistry())
+	command.AddCommand(NewCmdBuild())
+	command.AddCommand(NewCmdLogs())
 	return command
 }
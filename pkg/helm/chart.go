@@ -5,6 +5,7 @@ import (
 
 	"helm.sh/helm/v3/pkg/action"
 	"helm.sh/helm/v3/pkg/chart"
+	"helm.sh/helm/v3/pkg/chartutil"
 	"helm.sh/helm/v3/pkg/release"
 )
 
@@ -39,18 +40,31 @@ func (o *Chart) Install(namespace string, releaseName string, dryRun bool, value
 	return installAction.Run(o.chart, values)
 }
 
-func (o *Chart) Update(namespace string, releaseName string, dryRun bool, values map[string]interface{}) (*release.Release, error) {
+// Update upgrades the release to the chart. The values are merged with the
+// values supplied to previous versions of the release, defaults missing in those
+// are taken from the current chart. The tables at the replaced paths replace
+// the tables of the release instead of being merged with them.
+func (o *Chart) Update(namespace string, releaseName string, dryRun bool, values map[string]interface{}, replacedPaths ...string) (*release.Release, error) {
 	helmConfiguration, err := GetConfiguration(namespace)
 	if err != nil {
 		return nil, err
 	}
 
+	current, err := action.NewGet(helmConfiguration).Run(releaseName)
+	if err != nil {
+		return nil, err
+	}
+
 	upgradeAction := action.NewUpgrade(helmConfiguration)
 	upgradeAction.Namespace = namespace
 	upgradeAction.DryRun = dryRun
-	upgradeAction.ReuseValues = true
+	// reusing the values would also reuse the defaults of the chart the
+	// release was created with, which lack the values added since
+	upgradeAction.ResetValues = true
+
+	replaceTables(values, current.Config, replacedPaths)
 
-	return upgradeAction.Run(releaseName, o.chart, values)
+	return upgradeAction.Run(releaseName, o.chart, chartutil.CoalesceTables(values, current.Config))
 }
 
 func (o *Chart) Delete(namespace string, releaseName string, dryRun bool) (*release.UninstallReleaseResponse, error) {
@@ -0,0 +1,52 @@
+package helm
+
+import "strings"
+
+// lookupTable returns the table at the dot separated path
+func lookupTable(values map[string]interface{}, path string) (map[string]interface{}, bool) {
+	var node interface{} = values
+
+	for _, key := range strings.Split(path, ".") {
+		table, ok := node.(map[string]interface{})
+		if !ok {
+			return nil, false
+		}
+		node = table[key]
+	}
+
+	table, ok := node.(map[string]interface{})
+	return table, ok
+}
+
+// nullMissingKeys sets the keys of the current table which are missing in the
+// new table to nil, helm deletes keys with nil values when merging tables
+func nullMissingKeys(values map[string]interface{}, current map[string]interface{}) {
+	for key, currentValue := range current {
+		value, ok := values[key]
+		if !ok {
+			values[key] = nil
+			continue
+		}
+
+		table, isTable := value.(map[string]interface{})
+		currentTable, isCurrentTable := currentValue.(map[string]interface{})
+		if isTable && isCurrentTable {
+			nullMissingKeys(table, currentTable)
+		}
+	}
+}
+
+// replaceTables makes the tables at the paths replace the tables of the
+// release instead of being merged with them
+func replaceTables(values map[string]interface{}, current map[string]interface{}, paths []string) {
+	for _, path := range paths {
+		table, ok := lookupTable(values, path)
+		if !ok {
+			continue
+		}
+
+		if currentTable, ok := lookupTable(current, path); ok {
+			nullMissingKeys(table, currentTable)
+		}
+	}
+}
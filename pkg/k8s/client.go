@@ -3,9 +3,12 @@ package k8s
 import (
 	"bytes"
 	"fmt"
+	"net"
 	"net/http"
+	"strconv"
 	"sync"
 
+	v1 "k8s.io/api/core/v1"
 	"k8s.io/apimachinery/pkg/util/httpstream"
 	"k8s.io/client-go/kubernetes"
 	_ "k8s.io/client-go/plugin/pkg/client/auth/oidc"
@@ -50,6 +53,44 @@ func (o *Client) CreateDialer(name string, namespace string) (*httpstream.Dialer
 	return &dialer, nil
 }
 
+// DialPort opens a single connection to a port of a pod through the port forward
+// subresource without listening on a local port
+func (o *Client) DialPort(name string, namespace string, port uint16) (net.Conn, error) {
+	dialer, err := o.CreateDialer(name, namespace)
+	if err != nil {
+		return nil, err
+	}
+
+	connection, _, err := (*dialer).Dial(portforward.PortForwardProtocolV1Name)
+	if err != nil {
+		return nil, err
+	}
+
+	headers := http.Header{}
+	headers.Set(v1.StreamType, v1.StreamTypeError)
+	headers.Set(v1.PortHeader, strconv.Itoa(int(port)))
+	headers.Set(v1.PortForwardRequestIDHeader, "0")
+
+	errorStream, err := connection.CreateStream(headers)
+	if err != nil {
+		connection.Close()
+		return nil, err
+	}
+
+	// the error stream is only read from
+	errorStream.Close()
+
+	headers.Set(v1.StreamType, v1.StreamTypeData)
+
+	dataStream, err := connection.CreateStream(headers)
+	if err != nil {
+		connection.Close()
+		return nil, err
+	}
+
+	return newStreamConn(connection, dataStream, errorStream, fmt.Sprintf("%s/%s:%d", namespace, name, port)), nil
+}
+
 func (o *Client) ForwardPorts(name string, namespace string, ports []string) (PortForward, error) {
 	dialer, err := o.CreateDialer(name, namespace)
 	if err != nil {
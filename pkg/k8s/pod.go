@@ -7,9 +7,11 @@ import (
 	"io"
 	"net/http"
 	"os"
+	"sync"
 	"time"
 
 	"github.com/salberternst/workspace/pkg/utils"
+	appsv1 "k8s.io/api/apps/v1"
 	apiv1 "k8s.io/api/core/v1"
 	v1 "k8s.io/api/core/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
@@ -86,12 +88,26 @@ func GetWorkspacePod(namespace string, workspacName string) (*v1.Pod, error) {
 	return &pods.Items[0], nil
 }
 
-func GetPodLogs(pod v1.Pod, container string, follow bool) error {
-	podLogOpts := v1.PodLogOptions{
-		Follow:    follow,
-		Container: container,
+// GetWorkspacePods returns the pods of all workspaces matching the label selector
+func GetWorkspacePods(namespace string, selector string) ([]v1.Pod, error) {
+	labelSelector := "workspace-name"
+	if selector != "" {
+		labelSelector += "," + selector
+	}
+
+	pods, err := GetClient().CoreV1.CoreV1().Pods(namespace).List(context.TODO(), metav1.ListOptions{
+		LabelSelector: labelSelector,
+	})
+
+	if err != nil {
+		return nil, err
 	}
 
+	return pods.Items, nil
+}
+
+// GetPodLogs writes the logs of a container of the pod to stdout
+func GetPodLogs(pod *v1.Pod, podLogOpts v1.PodLogOptions) error {
 	stream, err := GetClient().CoreV1.CoreV1().Pods(pod.Namespace).GetLogs(pod.Name, &podLogOpts).Stream(context.TODO())
 	if err != nil {
 		return err
@@ -115,7 +131,38 @@ func GetPodLogs(pod v1.Pod, container string, follow bool) error {
 	}
 }
 
-func ExecuteInPod(namespace string, name string, container string, command []string, terminal bool) error {
+// ExecStreams defines which streams are attached to a command executed in a pod.
+// Streams which are nil are not requested from the api server.
+type ExecStreams struct {
+	Stdin  io.Reader
+	Stdout io.Writer
+	Stderr io.Writer
+	Tty    bool
+}
+
+// StandardStreams attaches the streams of the current process
+func StandardStreams(stdin bool, tty bool) ExecStreams {
+	streams := ExecStreams{
+		Stdout: os.Stdout,
+		Stderr: os.Stderr,
+		Tty:    tty,
+	}
+
+	if stdin || tty {
+		streams.Stdin = os.Stdin
+	}
+
+	return streams
+}
+
+// ExecuteInPod runs the command in the container. If the command exits with a
+// non-zero status the error returned by the executor is an exec.CodeExitError.
+func ExecuteInPod(namespace string, name string, container string, command []string, streams ExecStreams) error {
+	// stderr is merged into stdout by the remote terminal
+	if streams.Tty {
+		streams.Stderr = nil
+	}
+
 	req := GetClient().CoreV1.CoreV1().RESTClient().Post().
 		Resource("pods").
 		Name(name).
@@ -124,10 +171,10 @@ func ExecuteInPod(namespace string, name string, container string, command []str
 		VersionedParams(&v1.PodExecOptions{
 			Container: container,
 			Command:   command,
-			Stdin:     true,
-			Stdout:    true,
-			Stderr:    true,
-			TTY:       terminal,
+			Stdin:     streams.Stdin != nil,
+			Stdout:    streams.Stdout != nil,
+			Stderr:    streams.Stderr != nil,
+			TTY:       streams.Tty,
 		}, scheme.ParameterCodec)
 
 	exec, err := remotecommand.NewSPDYExecutor(GetClient().Config, http.MethodPost, req.URL())
@@ -136,7 +183,7 @@ func ExecuteInPod(namespace string, name string, container string, command []str
 	}
 
 	var sizeQueue remotecommand.TerminalSizeQueue
-	if terminal {
+	if streams.Tty {
 		terminal, err := utils.NewTerminal()
 		if err != nil {
 			return err
@@ -149,15 +196,271 @@ func ExecuteInPod(namespace string, name string, container string, command []str
 		defer terminal.Close()
 	}
 
-	if err = exec.Stream(remotecommand.StreamOptions{
-		Stdin:             os.Stdin,
-		Stdout:            os.Stdout,
-		Stderr:            os.Stderr,
-		Tty:               terminal,
+	return exec.Stream(remotecommand.StreamOptions{
+		Stdin:             streams.Stdin,
+		Stdout:            streams.Stdout,
+		Stderr:            streams.Stderr,
+		Tty:               streams.Tty,
 		TerminalSizeQueue: sizeQueue,
-	}); err != nil {
-		return err
+	})
+}
+
+// DeletePod deletes the pod, the statefulset of a workspace recreates it
+func DeletePod(namespace string, name string) error {
+	return GetClient().CoreV1.CoreV1().Pods(namespace).Delete(context.TODO(), name, metav1.DeleteOptions{})
+}
+
+const (
+	WorkspaceStatusPending      = "Pending"
+	WorkspaceStatusInitializing = "Initializing"
+	WorkspaceStatusStarting     = "Starting"
+	WorkspaceStatusReady        = "Ready"
+	WorkspaceStatusTerminating  = "Terminating"
+	WorkspaceStatusFailed       = "Failed"
+)
+
+func hasPodCondition(pod *v1.Pod, conditionType v1.PodConditionType) bool {
+	for _, condition := range pod.Status.Conditions {
+		if condition.Type == conditionType {
+			return condition.Status == v1.ConditionTrue
+		}
+	}
+	return false
+}
+
+// waitingReason returns why a container can not start, e.g. ImagePullBackOff.
+// The usual reasons while a pod starts are ignored.
+func waitingReason(pod *v1.Pod) string {
+	for _, status := range pod.Status.InitContainerStatuses {
+		if status.State.Waiting != nil && status.State.Waiting.Reason != "PodInitializing" {
+			return "Init:" + status.State.Waiting.Reason
+		}
+	}
+
+	for _, status := range pod.Status.ContainerStatuses {
+		if status.State.Waiting != nil && status.State.Waiting.Reason != "ContainerCreating" && status.State.Waiting.Reason != "PodInitializing" {
+			return status.State.Waiting.Reason
+		}
+	}
+
+	return ""
+}
+
+// GetWorkspaceStatus describes the state of the workspace pod. The workspace is
+// starting until its readiness probe succeeded, i.e. sshd accepts connections.
+func GetWorkspaceStatus(pod *v1.Pod) string {
+	if pod.DeletionTimestamp != nil {
+		return WorkspaceStatusTerminating
+	}
+
+	if pod.Status.Phase == v1.PodFailed || pod.Status.Phase == v1.PodSucceeded {
+		return WorkspaceStatusFailed
+	}
+
+	if hasPodCondition(pod, v1.PodReady) {
+		return WorkspaceStatusReady
+	}
+
+	if reason := waitingReason(pod); reason != "" {
+		return reason
+	}
+
+	if !hasPodCondition(pod, v1.PodScheduled) {
+		return WorkspaceStatusPending
+	}
+
+	if !hasPodCondition(pod, v1.PodInitialized) {
+		return WorkspaceStatusInitializing
+	}
+
+	return WorkspaceStatusStarting
+}
+
+// WaitForWorkspacePodReady waits until the pod of the workspace is ready and
+// prints every change of its status
+func WaitForWorkspacePodReady(namespace string, name string, waitTimeout uint) (*v1.Pod, error) {
+	watcher, err := GetClient().CoreV1.CoreV1().Pods(namespace).Watch(context.TODO(), metav1.ListOptions{
+		LabelSelector: "workspace-name=" + name,
+	})
+
+	if err != nil {
+		return nil, err
+	}
+
+	defer watcher.Stop()
+
+	var lastStatus string
+	var lock sync.Mutex
+
+	ready := make(chan *v1.Pod, 1)
+	failed := make(chan error, 1)
+	go func() {
+		for event := range watcher.ResultChan() {
+			if event.Object == nil {
+				return
+			}
+
+			pod, ok := event.Object.(*v1.Pod)
+			if !ok || event.Type == watch.Deleted {
+				continue
+			}
+
+			status := GetWorkspaceStatus(pod)
+
+			lock.Lock()
+			if status != lastStatus {
+				fmt.Fprintf(os.Stderr, "Workspace %s in namespace %s: %s\n", name, namespace, status)
+				lastStatus = status
+			}
+			lock.Unlock()
+
+			if status == WorkspaceStatusReady {
+				ready <- pod
+				return
+			}
+
+			if err := getInitContainerError(pod); err != nil {
+				failed <- err
+				return
+			}
+		}
+	}()
+
+	select {
+	case pod := <-ready:
+		return pod, nil
+	case err := <-failed:
+		return nil, err
+	case <-time.After(time.Duration(waitTimeout) * time.Second):
+		lock.Lock()
+		defer lock.Unlock()
+		return nil, fmt.Errorf("Timeout occured after %d seconds while waiting for the workspace to become ready (%s)", waitTimeout, lastStatus)
+	}
+}
+
+// InitContainerError reports an init container of the workspace which exited
+// with an error, e.g. because packages could not be installed
+type InitContainerError struct {
+	Pod       string
+	Container string
+	ExitCode  int32
+	Logs      string
+}
+
+func (e *InitContainerError) Error() string {
+	return fmt.Sprintf("Init container %s of pod %s failed with exit code %d:\n%s", e.Container, e.Pod, e.ExitCode, e.Logs)
+}
+
+// initContainerLogLines limits the logs shown for a failed init container
+const initContainerLogLines = 50
+
+// GetContainerLogs returns the last lines of the logs of a container. If
+// previous is set, the logs of the last terminated instance are returned.
+func GetContainerLogs(pod *v1.Pod, container string, previous bool, tailLines int64) (string, error) {
+	podLogOpts := v1.PodLogOptions{
+		Container: container,
+		Previous:  previous,
+		TailLines: &tailLines,
+	}
+
+	stream, err := GetClient().CoreV1.CoreV1().Pods(pod.Namespace).GetLogs(pod.Name, &podLogOpts).Stream(context.TODO())
+	if err != nil {
+		return "", err
+	}
+
+	defer stream.Close()
+
+	logs, err := io.ReadAll(stream)
+	if err != nil {
+		return "", err
+	}
+
+	return string(logs), nil
+}
+
+// getInitContainerError returns an error with the logs of the first init
+// container of the pod which failed or nil if all succeeded so far
+func getInitContainerError(pod *v1.Pod) error {
+	// init containers which failed before but succeeded on a retry
+	if hasPodCondition(pod, v1.PodInitialized) {
+		return nil
+	}
+
+	for _, status := range pod.Status.InitContainerStatuses {
+		// a failed init container is restarted, the logs are kept for the
+		// previous instance until it terminates again
+		terminated, previous := status.State.Terminated, false
+		if terminated == nil {
+			terminated, previous = status.LastTerminationState.Terminated, true
+		}
+
+		if terminated == nil || terminated.ExitCode == 0 {
+			continue
+		}
+
+		logs, err := GetContainerLogs(pod, status.Name, previous, initContainerLogLines)
+		if err != nil {
+			logs = fmt.Sprintf("Failed to get logs: %s", err.Error())
+		}
+
+		return &InitContainerError{
+			Pod:       pod.Name,
+			Container: status.Name,
+			ExitCode:  terminated.ExitCode,
+			Logs:      logs,
+		}
 	}
 
 	return nil
 }
+
+// WaitForWorkspaceReady waits until the pod of the latest spec of the workspace
+// is ready. It fails early if an init container of that pod fails, pods of
+// previous revisions are ignored.
+func WaitForWorkspaceReady(name string, namespace string, waitTimeout uint) error {
+	watcher, err := GetClient().CoreV1.CoreV1().Pods(namespace).Watch(context.TODO(), metav1.ListOptions{
+		LabelSelector: "workspace-name=" + name,
+	})
+
+	if err != nil {
+		return err
+	}
+
+	defer watcher.Stop()
+
+	failed := make(chan error, 1)
+	go func() {
+		for event := range watcher.ResultChan() {
+			if event.Object == nil {
+				return
+			}
+
+			pod, ok := event.Object.(*v1.Pod)
+			if !ok || event.Type == watch.Deleted {
+				continue
+			}
+
+			updateRevision, err := GetStatefulSetUpdateRevision(name, namespace)
+			if err != nil || updateRevision == "" || pod.Labels[appsv1.ControllerRevisionHashLabelKey] != updateRevision {
+				continue
+			}
+
+			if err := getInitContainerError(pod); err != nil {
+				failed <- err
+				return
+			}
+		}
+	}()
+
+	ready := make(chan error, 1)
+	go func() {
+		ready <- WaitForStatefulSetReplicaReady(name, namespace, waitTimeout)
+	}()
+
+	select {
+	case err := <-ready:
+		return err
+	case err := <-failed:
+		return err
+	}
+}
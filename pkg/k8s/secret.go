@@ -2,8 +2,11 @@ package k8s
 
 import (
 	"context"
+	"fmt"
 
+	appsv1 "k8s.io/api/apps/v1"
 	v1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/errors"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 )
 
@@ -15,3 +18,152 @@ func ReadSecret(name string, namespace string) (*v1.Secret, error) {
 
 	return secret, err
 }
+
+// WorkspaceEnvSecretName is the secret whose keys the chart sets as environment
+// variables of the workspace container. The suffix must not clash with the
+// names the chart derives from other release names, e.g. the ssh secret of a
+// workspace named <name>-env.
+func WorkspaceEnvSecretName(name string) string {
+	return name + "-workspace-env"
+}
+
+// checkWorkspaceEnvSecret checks that the secret was created for the workspace
+// by SetWorkspaceSecretValue and not e.g. by the chart of another workspace
+func checkWorkspaceEnvSecret(secret *v1.Secret, name string, namespace string) error {
+	if secret.Labels["workspace-name"] == name {
+		for _, ownerReference := range secret.OwnerReferences {
+			if ownerReference.Kind == "StatefulSet" && ownerReference.Name == name {
+				return nil
+			}
+		}
+	}
+
+	return fmt.Errorf("Secret %s in namespace %s does not belong to workspace %s", secret.Name, namespace, name)
+}
+
+// SetWorkspaceSecretValue stores the value in the env secret of the workspace.
+// The secret is owned by the statefulset so it is deleted with the workspace.
+func SetWorkspaceSecretValue(name string, namespace string, key string, value []byte) error {
+	secrets := GetClient().CoreV1.CoreV1().Secrets(namespace)
+
+	secret, err := secrets.Get(context.TODO(), WorkspaceEnvSecretName(name), metav1.GetOptions{})
+	if errors.IsNotFound(err) {
+		statefulSet, err := GetStatefulSet(name, namespace)
+		if err != nil {
+			return err
+		}
+
+		_, err = secrets.Create(context.TODO(), &v1.Secret{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      WorkspaceEnvSecretName(name),
+				Namespace: namespace,
+				Labels: map[string]string{
+					"workspace-name": name,
+				},
+				OwnerReferences: []metav1.OwnerReference{
+					*metav1.NewControllerRef(statefulSet, appsv1.SchemeGroupVersion.WithKind("StatefulSet")),
+				},
+			},
+			Type: v1.SecretTypeOpaque,
+			Data: map[string][]byte{
+				key: value,
+			},
+		}, metav1.CreateOptions{})
+
+		return err
+	} else if err != nil {
+		return err
+	}
+
+	if err := checkWorkspaceEnvSecret(secret, name, namespace); err != nil {
+		return err
+	}
+
+	if secret.Data == nil {
+		secret.Data = map[string][]byte{}
+	}
+	secret.Data[key] = value
+
+	_, err = secrets.Update(context.TODO(), secret, metav1.UpdateOptions{})
+	return err
+}
+
+// DeleteWorkspaceSecretValue removes the key from the env secret of the
+// workspace, it returns false if the key did not exist
+func DeleteWorkspaceSecretValue(name string, namespace string, key string) (bool, error) {
+	secrets := GetClient().CoreV1.CoreV1().Secrets(namespace)
+
+	secret, err := secrets.Get(context.TODO(), WorkspaceEnvSecretName(name), metav1.GetOptions{})
+	if errors.IsNotFound(err) {
+		return false, nil
+	} else if err != nil {
+		return false, err
+	}
+
+	if err := checkWorkspaceEnvSecret(secret, name, namespace); err != nil {
+		return false, err
+	}
+
+	if _, found := secret.Data[key]; !found {
+		return false, nil
+	}
+	delete(secret.Data, key)
+
+	_, err = secrets.Update(context.TODO(), secret, metav1.UpdateOptions{})
+	return err == nil, err
+}
+
+// GetWorkspaceSecretKeys returns the keys of the env secret of the workspace
+func GetWorkspaceSecretKeys(name string, namespace string) ([]string, error) {
+	secret, err := GetClient().CoreV1.CoreV1().Secrets(namespace).Get(context.TODO(), WorkspaceEnvSecretName(name), metav1.GetOptions{})
+	if errors.IsNotFound(err) {
+		return []string{}, nil
+	} else if err != nil {
+		return nil, err
+	}
+
+	if err := checkWorkspaceEnvSecret(secret, name, namespace); err != nil {
+		return nil, err
+	}
+
+	keys := make([]string, 0, len(secret.Data))
+	for key := range secret.Data {
+		keys = append(keys, key)
+	}
+
+	return keys, nil
+}
+
+// ApplyDockerRegistrySecret creates or updates an image pull secret
+func ApplyDockerRegistrySecret(name string, namespace string, dockerConfigJson []byte) error {
+	secrets := GetClient().CoreV1.CoreV1().Secrets(namespace)
+
+	secret, err := secrets.Get(context.TODO(), name, metav1.GetOptions{})
+	if errors.IsNotFound(err) {
+		_, err = secrets.Create(context.TODO(), &v1.Secret{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      name,
+				Namespace: namespace,
+			},
+			Type: v1.SecretTypeDockerConfigJson,
+			Data: map[string][]byte{
+				v1.DockerConfigJsonKey: dockerConfigJson,
+			},
+		}, metav1.CreateOptions{})
+
+		return err
+	} else if err != nil {
+		return err
+	}
+
+	if secret.Type != v1.SecretTypeDockerConfigJson {
+		return fmt.Errorf("Secret %s in namespace %s exists but is of type %s", name, namespace, secret.Type)
+	}
+
+	secret.Data = map[string][]byte{
+		v1.DockerConfigJsonKey: dockerConfigJson,
+	}
+
+	_, err = secrets.Update(context.TODO(), secret, metav1.UpdateOptions{})
+	return err
+}
@@ -14,6 +14,23 @@ func GetStatefulSet(name, namespace string) (*v1.StatefulSet, error) {
 	return GetClient().CoreV1.AppsV1().StatefulSets(namespace).Get(context.TODO(), name, metav1.GetOptions{})
 }
 
+// statefulSetObserved checks that the controller has seen the latest spec of the
+// statefulset, before that the revisions and replica counts of the status are
+// those of the previous spec
+func statefulSetObserved(statefulSet *v1.StatefulSet) bool {
+	return statefulSet.Status.ObservedGeneration >= statefulSet.Generation
+}
+
+// statefulSetRolledOut checks that the pods of the latest spec replaced the
+// previous ones and are ready
+func statefulSetRolledOut(statefulSet *v1.StatefulSet) bool {
+	return statefulSetObserved(statefulSet) &&
+		statefulSet.Status.UpdateRevision == statefulSet.Status.CurrentRevision &&
+		statefulSet.Status.ReadyReplicas > 0
+}
+
+// WaitForStatefulSetReplica waits until a pod of the latest spec of the
+// statefulset was created
 func WaitForStatefulSetReplica(name, namespace string, waitTimeout uint) error {
 	watcher, err := GetClient().CoreV1.AppsV1().StatefulSets(namespace).Watch(context.TODO(), metav1.ListOptions{
 		FieldSelector: fields.OneTermEqualSelector("metadata.name", name).String(),
@@ -37,8 +54,9 @@ func WaitForStatefulSetReplica(name, namespace string, waitTimeout uint) error {
 				continue
 			}
 
-			if statefulSet.Status.Replicas > 0 {
+			if statefulSetObserved(statefulSet) && statefulSet.Status.UpdatedReplicas > 0 {
 				ready <- true
+				return
 			}
 		}
 	}()
@@ -51,6 +69,9 @@ func WaitForStatefulSetReplica(name, namespace string, waitTimeout uint) error {
 	}
 }
 
+// WaitForStatefulSetReplicaReady waits until the pods of the latest spec of the
+// statefulset replaced the previous ones and are ready, on update the previous
+// pod is still ready until it is replaced
 func WaitForStatefulSetReplicaReady(name, namespace string, waitTimeout uint) error {
 	watcher, err := GetClient().CoreV1.AppsV1().StatefulSets(namespace).Watch(context.TODO(), metav1.ListOptions{
 		FieldSelector: fields.OneTermEqualSelector("metadata.name", name).String(),
@@ -74,8 +95,9 @@ func WaitForStatefulSetReplicaReady(name, namespace string, waitTimeout uint) er
 				continue
 			}
 
-			if statefulSet.Status.ReadyReplicas > 0 {
+			if statefulSetRolledOut(statefulSet) {
 				ready <- true
+				return
 			}
 		}
 	}()
@@ -87,3 +109,18 @@ func WaitForStatefulSetReplicaReady(name, namespace string, waitTimeout uint) er
 		return fmt.Errorf("Timeout occured after %d seconds while waiting for the workspace to become ready", waitTimeout)
 	}
 }
+
+// GetStatefulSetUpdateRevision returns the revision of the latest spec of the
+// statefulset, it is empty until the controller has seen the latest spec
+func GetStatefulSetUpdateRevision(name, namespace string) (string, error) {
+	statefulSet, err := GetStatefulSet(name, namespace)
+	if err != nil {
+		return "", err
+	}
+
+	if !statefulSetObserved(statefulSet) {
+		return "", nil
+	}
+
+	return statefulSet.Status.UpdateRevision, nil
+}
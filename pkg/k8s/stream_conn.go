@@ -0,0 +1,123 @@
+package k8s
+
+import (
+	"fmt"
+	"io"
+	"net"
+	"strings"
+	"sync"
+	"time"
+
+	"k8s.io/apimachinery/pkg/util/httpstream"
+)
+
+// streamAddr describes one end of a port forward stream
+type streamAddr struct {
+	address string
+}
+
+func (o streamAddr) Network() string {
+	return "portforward"
+}
+
+func (o streamAddr) String() string {
+	return o.address
+}
+
+// streamConn wraps the data stream of a port forward as a net.Conn so it can be
+// used directly by clients (e.g. ssh) without binding a local port
+type streamConn struct {
+	connection  httpstream.Connection
+	dataStream  httpstream.Stream
+	errorStream httpstream.Stream
+	remoteAddr  streamAddr
+	closeOnce   sync.Once
+	errorDone   chan struct{}
+	remoteError error
+}
+
+func newStreamConn(connection httpstream.Connection, dataStream httpstream.Stream, errorStream httpstream.Stream, remoteAddr string) *streamConn {
+	conn := &streamConn{
+		connection:  connection,
+		dataStream:  dataStream,
+		errorStream: errorStream,
+		remoteAddr:  streamAddr{address: remoteAddr},
+		errorDone:   make(chan struct{}),
+	}
+
+	go conn.readErrors()
+
+	return conn
+}
+
+func (o *streamConn) readErrors() {
+	defer close(o.errorDone)
+
+	message, err := io.ReadAll(o.errorStream)
+	if err != nil {
+		return
+	}
+
+	if len(message) > 0 {
+		o.remoteError = fmt.Errorf("port forward to %s failed: %s", o.remoteAddr, strings.TrimSpace(string(message)))
+	}
+}
+
+// wrapError replaces a stream error with the error reported by the kubelet if there is one
+func (o *streamConn) wrapError(err error) error {
+	select {
+	case <-o.errorDone:
+		if o.remoteError != nil {
+			return o.remoteError
+		}
+	default:
+	}
+	return err
+}
+
+func (o *streamConn) Read(b []byte) (int, error) {
+	n, err := o.dataStream.Read(b)
+	if err != nil && err != io.EOF {
+		return n, o.wrapError(err)
+	}
+	return n, err
+}
+
+func (o *streamConn) Write(b []byte) (int, error) {
+	n, err := o.dataStream.Write(b)
+	if err != nil {
+		return n, o.wrapError(err)
+	}
+	return n, nil
+}
+
+func (o *streamConn) Close() error {
+	var err error
+	o.closeOnce.Do(func() {
+		o.dataStream.Close()
+		o.connection.RemoveStreams(o.dataStream, o.errorStream)
+		err = o.connection.Close()
+	})
+	return err
+}
+
+func (o *streamConn) LocalAddr() net.Addr {
+	return streamAddr{address: "local"}
+}
+
+func (o *streamConn) RemoteAddr() net.Addr {
+	return o.remoteAddr
+}
+
+// deadlines are not supported by the underlying spdy streams
+func (o *streamConn) SetDeadline(t time.Time) error {
+	return nil
+}
+
+func (o *streamConn) SetReadDeadline(t time.Time) error {
+	return nil
+}
+
+func (o *streamConn) SetWriteDeadline(t time.Time) error {
+	return nil
+}
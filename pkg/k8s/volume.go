@@ -2,9 +2,16 @@ package k8s
 
 import (
 	"context"
+	"fmt"
+	"strings"
+	"sync"
+	"time"
 
 	v1 "k8s.io/api/core/v1"
+	storagev1 "k8s.io/api/storage/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/fields"
 )
 
 func GetWorkspaceVolumes(namespace string, notebookName string) ([]v1.PersistentVolumeClaim, error) {
@@ -22,3 +29,94 @@ func GetWorkspaceVolumes(namespace string, notebookName string) ([]v1.Persistent
 
 	return volumes.Items, nil
 }
+
+func GetPersistentVolumeClaim(name string, namespace string) (*v1.PersistentVolumeClaim, error) {
+	return GetClient().CoreV1.CoreV1().PersistentVolumeClaims(namespace).Get(context.TODO(), name, metav1.GetOptions{})
+}
+
+func GetStorageClass(name string) (*storagev1.StorageClass, error) {
+	return GetClient().CoreV1.StorageV1().StorageClasses().Get(context.TODO(), name, metav1.GetOptions{})
+}
+
+// volumeResized checks if the capacity of the volume reached the size and no
+// resize of the controller or the file system is pending
+func volumeResized(volume *v1.PersistentVolumeClaim, size resource.Quantity) bool {
+	capacity, found := volume.Status.Capacity[v1.ResourceStorage]
+	if !found || capacity.Cmp(size) < 0 {
+		return false
+	}
+
+	for _, condition := range volume.Status.Conditions {
+		if condition.Type == v1.PersistentVolumeClaimResizing || condition.Type == v1.PersistentVolumeClaimFileSystemResizePending {
+			return false
+		}
+	}
+
+	return true
+}
+
+// describeVolumeConditions returns the messages of the conditions of the volume
+func describeVolumeConditions(volume *v1.PersistentVolumeClaim) string {
+	var messages []string
+	for _, condition := range volume.Status.Conditions {
+		message := string(condition.Type)
+		if condition.Message != "" {
+			message += ": " + condition.Message
+		}
+		messages = append(messages, message)
+	}
+	return strings.Join(messages, ", ")
+}
+
+// WaitForVolumeResize waits until the file system of the volume was resized to
+// at least the size
+func WaitForVolumeResize(name string, namespace string, size resource.Quantity, waitTimeout uint) error {
+	watcher, err := GetClient().CoreV1.CoreV1().PersistentVolumeClaims(namespace).Watch(context.TODO(), metav1.ListOptions{
+		FieldSelector: fields.OneTermEqualSelector("metadata.name", name).String(),
+	})
+
+	if err != nil {
+		return err
+	}
+
+	defer watcher.Stop()
+
+	var lastVolume *v1.PersistentVolumeClaim
+	var lock sync.Mutex
+
+	ready := make(chan bool, 1)
+	go func() {
+		for event := range watcher.ResultChan() {
+			if event.Object == nil {
+				return
+			}
+
+			volume, ok := event.Object.(*v1.PersistentVolumeClaim)
+			if !ok {
+				continue
+			}
+
+			lock.Lock()
+			lastVolume = volume
+			lock.Unlock()
+
+			if volumeResized(volume, size) {
+				ready <- true
+				return
+			}
+		}
+	}()
+
+	select {
+	case <-ready:
+		return nil
+	case <-time.After(time.Duration(waitTimeout) * time.Second):
+		lock.Lock()
+		defer lock.Unlock()
+
+		if lastVolume != nil && len(lastVolume.Status.Conditions) > 0 {
+			return fmt.Errorf("Timeout occured after %d seconds while waiting for volume %s to be resized (%s)", waitTimeout, name, describeVolumeConditions(lastVolume))
+		}
+		return fmt.Errorf("Timeout occured after %d seconds while waiting for volume %s to be resized", waitTimeout, name)
+	}
+}
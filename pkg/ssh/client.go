@@ -0,0 +1,171 @@
+package ssh
+
+import (
+	"errors"
+	"net"
+	"os"
+
+	"github.com/salberternst/workspace/pkg/utils"
+	cryptossh "golang.org/x/crypto/ssh"
+	"k8s.io/client-go/util/exec"
+)
+
+const defaultTerm = "xterm-256color"
+
+type Client struct {
+	client    *cryptossh.Client
+	listeners []net.Listener
+}
+
+// NewClient establishes a ssh connection over conn. The workspace uses the same
+// key as host key and as authorized key, so it is used for both directions.
+func NewClient(conn net.Conn, username string, privateKey []byte) (*Client, error) {
+	signer, err := cryptossh.ParsePrivateKey(privateKey)
+	if err != nil {
+		return nil, err
+	}
+
+	config := &cryptossh.ClientConfig{
+		User:            username,
+		Auth:            []cryptossh.AuthMethod{cryptossh.PublicKeys(signer)},
+		HostKeyCallback: cryptossh.FixedHostKey(signer.PublicKey()),
+	}
+
+	sshConn, channels, requests, err := cryptossh.NewClientConn(conn, conn.RemoteAddr().String(), config)
+	if err != nil {
+		return nil, err
+	}
+
+	return &Client{
+		client: cryptossh.NewClient(sshConn, channels, requests),
+	}, nil
+}
+
+// Run executes the command in a new session or starts a login shell if no
+// command is given. A non-zero remote exit status is returned as exec.CodeExitError.
+func (o *Client) Run(command []string, tty bool) error {
+	session, err := o.client.NewSession()
+	if err != nil {
+		return err
+	}
+
+	defer session.Close()
+
+	session.Stdin = os.Stdin
+	session.Stdout = os.Stdout
+	session.Stderr = os.Stderr
+
+	if tty {
+		terminal, err := utils.NewTerminal()
+		if err != nil {
+			return err
+		}
+
+		defer terminal.Close()
+
+		terminal.MonitorSize()
+
+		if err := requestPty(session, terminal); err != nil {
+			return err
+		}
+	}
+
+	if len(command) == 0 {
+		err = session.Shell()
+	} else {
+		err = session.Start(utils.QuoteCommand(command))
+	}
+
+	if err != nil {
+		return err
+	}
+
+	return toExitError(session.Wait())
+}
+
+func requestPty(session *cryptossh.Session, terminal *utils.Terminal) error {
+	termType := os.Getenv("TERM")
+	if termType == "" {
+		termType = defaultTerm
+	}
+
+	size := terminal.SizeQueue.Next()
+	if size == nil {
+		return errors.New("unable to determine the terminal size")
+	}
+
+	if err := session.RequestPty(termType, int(size.Height), int(size.Width), cryptossh.TerminalModes{
+		cryptossh.ECHO:          1,
+		cryptossh.TTY_OP_ISPEED: 14400,
+		cryptossh.TTY_OP_OSPEED: 14400,
+	}); err != nil {
+		return err
+	}
+
+	go func() {
+		for size := terminal.SizeQueue.Next(); size != nil; size = terminal.SizeQueue.Next() {
+			session.WindowChange(int(size.Height), int(size.Width))
+		}
+	}()
+
+	return nil
+}
+
+// ForwardLocal listens on the local address and forwards connections to the
+// host address as seen from the workspace
+func (o *Client) ForwardLocal(forward Forward) error {
+	listener, err := net.Listen("tcp", forward.listenAddress())
+	if err != nil {
+		return err
+	}
+
+	o.listeners = append(o.listeners, listener)
+
+	go serveForward(listener, func() (net.Conn, error) {
+		return o.client.Dial("tcp", forward.hostAddress())
+	})
+
+	return nil
+}
+
+// ForwardRemote listens on the address inside the workspace and forwards
+// connections to the host address as seen from the local machine
+func (o *Client) ForwardRemote(forward Forward) error {
+	listener, err := o.client.Listen("tcp", forward.listenAddress())
+	if err != nil {
+		return err
+	}
+
+	o.listeners = append(o.listeners, listener)
+
+	go serveForward(listener, func() (net.Conn, error) {
+		return net.Dial("tcp", forward.hostAddress())
+	})
+
+	return nil
+}
+
+// Wait blocks until the connection is closed
+func (o *Client) Wait() error {
+	return o.client.Wait()
+}
+
+func (o *Client) Close() error {
+	for _, listener := range o.listeners {
+		listener.Close()
+	}
+
+	return o.client.Close()
+}
+
+func toExitError(err error) error {
+	var exitError *cryptossh.ExitError
+	if errors.As(err, &exitError) {
+		return exec.CodeExitError{
+			Err:  err,
+			Code: exitError.ExitStatus(),
+		}
+	}
+
+	return err
+}
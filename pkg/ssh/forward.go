@@ -0,0 +1,107 @@
+package ssh
+
+import (
+	"fmt"
+	"io"
+	"net"
+	"strconv"
+	"strings"
+	"sync"
+)
+
+// Forward describes a port forward in the form of [bind_address:]port:host:hostport
+type Forward struct {
+	BindAddress string
+	Port        uint16
+	Host        string
+	HostPort    uint16
+}
+
+func ParseForward(value string) (Forward, error) {
+	parts := strings.Split(value, ":")
+
+	forward := Forward{
+		BindAddress: "127.0.0.1",
+	}
+
+	switch len(parts) {
+	case 3:
+	case 4:
+		forward.BindAddress = parts[0]
+		parts = parts[1:]
+	default:
+		return Forward{}, fmt.Errorf("Invalid port forward %s, expected [bind_address:]port:host:hostport", value)
+	}
+
+	port, err := strconv.ParseUint(parts[0], 10, 16)
+	if err != nil {
+		return Forward{}, fmt.Errorf("Invalid port %s in port forward %s", parts[0], value)
+	}
+
+	hostPort, err := strconv.ParseUint(parts[2], 10, 16)
+	if err != nil {
+		return Forward{}, fmt.Errorf("Invalid port %s in port forward %s", parts[2], value)
+	}
+
+	if parts[1] == "" {
+		return Forward{}, fmt.Errorf("Missing host in port forward %s", value)
+	}
+
+	forward.Port = uint16(port)
+	forward.Host = parts[1]
+	forward.HostPort = uint16(hostPort)
+
+	return forward, nil
+}
+
+func (o Forward) listenAddress() string {
+	return net.JoinHostPort(o.BindAddress, strconv.Itoa(int(o.Port)))
+}
+
+func (o Forward) hostAddress() string {
+	return net.JoinHostPort(o.Host, strconv.Itoa(int(o.HostPort)))
+}
+
+func (o Forward) String() string {
+	return o.listenAddress() + " -> " + o.hostAddress()
+}
+
+// serveForward accepts connections on the listener and connects each of them to
+// the connection returned by dial until the listener is closed
+func serveForward(listener net.Listener, dial func() (net.Conn, error)) {
+	for {
+		conn, err := listener.Accept()
+		if err != nil {
+			return
+		}
+
+		go func() {
+			defer conn.Close()
+
+			target, err := dial()
+			if err != nil {
+				return
+			}
+
+			defer target.Close()
+
+			pipe(conn, target)
+		}()
+	}
+}
+
+func pipe(a net.Conn, b net.Conn) {
+	var wg sync.WaitGroup
+	wg.Add(2)
+
+	copyAndClose := func(dst net.Conn, src net.Conn) {
+		defer wg.Done()
+		io.Copy(dst, src)
+		dst.Close()
+	}
+
+	go copyAndClose(a, b)
+	go copyAndClose(b, a)
+
+	wg.Wait()
+}
@@ -0,0 +1,62 @@
+package synchronization
+
+import (
+	"net"
+	"net/rpc"
+	"time"
+)
+
+const dialTimeout = 2 * time.Second
+
+// ControlClient controls the synchronization of a workspace running in another process
+type ControlClient struct {
+	client *rpc.Client
+}
+
+func Dial(name string, namespace string) (*ControlClient, error) {
+	socketPath, err := getSocketPath(name, namespace)
+	if err != nil {
+		return nil, err
+	}
+
+	conn, err := net.DialTimeout("unix", socketPath, dialTimeout)
+	if err != nil {
+		return nil, err
+	}
+
+	return &ControlClient{
+		client: rpc.NewClient(conn),
+	}, nil
+}
+
+func (o *ControlClient) Status() ([]SessionStatus, error) {
+	var statuses []SessionStatus
+	err := o.client.Call(controlServiceName+".Status", &Empty{}, &statuses)
+	return statuses, err
+}
+
+func (o *ControlClient) Conflicts() ([]Conflict, error) {
+	var conflicts []Conflict
+	err := o.client.Call(controlServiceName+".Conflicts", &Empty{}, &conflicts)
+	return conflicts, err
+}
+
+func (o *ControlClient) Flush() error {
+	return o.client.Call(controlServiceName+".Flush", &Empty{}, &Empty{})
+}
+
+func (o *ControlClient) Pause() error {
+	return o.client.Call(controlServiceName+".Pause", &Empty{}, &Empty{})
+}
+
+func (o *ControlClient) Resume() error {
+	return o.client.Call(controlServiceName+".Resume", &Empty{}, &Empty{})
+}
+
+func (o *ControlClient) Stop() error {
+	return o.client.Call(controlServiceName+".Stop", &Empty{}, &Empty{})
+}
+
+func (o *ControlClient) Close() error {
+	return o.client.Close()
+}
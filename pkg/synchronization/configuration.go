@@ -0,0 +1,139 @@
+package synchronization
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/mutagen-io/mutagen/pkg/filesystem"
+	"github.com/mutagen-io/mutagen/pkg/filesystem/behavior"
+	"github.com/mutagen-io/mutagen/pkg/synchronization"
+	"github.com/mutagen-io/mutagen/pkg/synchronization/core"
+)
+
+// SyncModes are the synchronization modes supported by mutagen
+var SyncModes = []string{"two-way-safe", "two-way-resolved", "one-way-safe", "one-way-replica"}
+
+// SymbolicLinkModes are the symbolic link modes supported by mutagen
+var SymbolicLinkModes = []string{"ignore", "portable", "posix-raw"}
+
+// EndpointConfigKeys are the keys of the configuration of a single endpoint
+var EndpointConfigKeys = []string{"file-mode", "directory-mode", "owner", "group", "probe-mode"}
+
+// SessionConfig is the configuration of the sessions of all folders. Empty
+// values fall back to the defaults of mutagen.
+type SessionConfig struct {
+	Mode             string
+	SymbolicLinkMode string
+	// Local and Remote configure the endpoints, see EndpointConfigKeys
+	Local  map[string]string
+	Remote map[string]string
+}
+
+func parseSyncMode(mode string) (core.SynchronizationMode, error) {
+	var syncMode core.SynchronizationMode
+	if mode == "" {
+		return syncMode, nil
+	}
+
+	if err := syncMode.UnmarshalText([]byte(mode)); err != nil {
+		return syncMode, fmt.Errorf("Invalid sync mode %s, allowed values are %s", mode, strings.Join(SyncModes, ", "))
+	}
+
+	return syncMode, nil
+}
+
+// ValidateSyncMode checks if mutagen supports the sync mode
+func ValidateSyncMode(mode string) error {
+	_, err := parseSyncMode(mode)
+	return err
+}
+
+// buildConfiguration returns the configuration of a session, the mode of the
+// folder takes precedence over the mode of the session config
+func (o SessionConfig) buildConfiguration(mode string) (*synchronization.Configuration, error) {
+	if mode == "" {
+		mode = o.Mode
+	}
+
+	configuration := &synchronization.Configuration{}
+
+	var err error
+	if configuration.SynchronizationMode, err = parseSyncMode(mode); err != nil {
+		return nil, err
+	}
+
+	if o.SymbolicLinkMode != "" {
+		if err := configuration.SymbolicLinkMode.UnmarshalText([]byte(o.SymbolicLinkMode)); err != nil {
+			return nil, fmt.Errorf("Invalid symbolic link mode %s, allowed values are %s", o.SymbolicLinkMode, strings.Join(SymbolicLinkModes, ", "))
+		}
+	}
+
+	return configuration, nil
+}
+
+// buildEndpointConfiguration converts the configuration of an endpoint, the
+// endpoint is only used in error messages
+func buildEndpointConfiguration(endpoint string, values map[string]string) (*synchronization.Configuration, error) {
+	configuration := &synchronization.Configuration{}
+
+	keys := make([]string, 0, len(values))
+	for key := range values {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	for _, key := range keys {
+		value := values[key]
+		// an empty value unsets a default
+		if value == "" {
+			continue
+		}
+
+		var err error
+		switch key {
+		case "file-mode":
+			var mode filesystem.Mode
+			err = mode.UnmarshalText([]byte(value))
+			configuration.DefaultFileMode = uint32(mode)
+		case "directory-mode":
+			var mode filesystem.Mode
+			err = mode.UnmarshalText([]byte(value))
+			configuration.DefaultDirectoryMode = uint32(mode)
+		case "owner":
+			configuration.DefaultOwner = value
+		case "group":
+			configuration.DefaultGroup = value
+		case "probe-mode":
+			var mode behavior.ProbeMode
+			err = mode.UnmarshalText([]byte(value))
+			configuration.ProbeMode = mode
+		default:
+			return nil, fmt.Errorf("Unknown key %s in %s configuration, allowed keys are %s", key, endpoint, strings.Join(EndpointConfigKeys, ", "))
+		}
+
+		if err != nil {
+			return nil, fmt.Errorf("Invalid value %s for %s in %s configuration: %w", value, key, endpoint, err)
+		}
+	}
+
+	if err := configuration.EnsureValid(true); err != nil {
+		return nil, fmt.Errorf("Invalid %s configuration: %w", endpoint, err)
+	}
+
+	return configuration, nil
+}
+
+// Validate checks the session config before any session is created
+func (o SessionConfig) Validate() error {
+	if _, err := o.buildConfiguration(""); err != nil {
+		return err
+	}
+
+	if _, err := buildEndpointConfiguration("local", o.Local); err != nil {
+		return err
+	}
+
+	_, err := buildEndpointConfiguration("remote", o.Remote)
+	return err
+}
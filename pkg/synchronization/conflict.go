@@ -0,0 +1,83 @@
+package synchronization
+
+import (
+	"fmt"
+	"path"
+	"path/filepath"
+	"strings"
+
+	"github.com/mutagen-io/mutagen/pkg/synchronization/core"
+)
+
+const (
+	ResolutionLocal    = "local"
+	ResolutionRemote   = "remote"
+	ResolutionKeepBoth = "keep-both"
+)
+
+var Resolutions = []string{ResolutionLocal, ResolutionRemote, ResolutionKeepBoth}
+
+// Conflict is a path which was changed both locally (alpha) and in the workspace (beta)
+type Conflict struct {
+	Session       string
+	Source        string
+	Target        string
+	LocalRoot     string
+	Path          string
+	LocalChanges  []string
+	RemoteChanges []string
+}
+
+// LocalPath is the absolute path of the conflict on the local machine
+func (o Conflict) LocalPath() string {
+	return filepath.Join(o.LocalRoot, filepath.FromSlash(o.Path))
+}
+
+// RemotePath is the path of the conflict in the workspace, paths below the
+// home folder (e.g. ~/code) are returned relative to the home folder as the
+// shell does not expand a quoted ~
+func (o Conflict) RemotePath() string {
+	target := o.Target
+	if strings.HasPrefix(target, "~") {
+		target = strings.TrimPrefix(strings.TrimPrefix(target, "~"), "/")
+	}
+
+	return path.Join(target, o.Path)
+}
+
+func describeEntry(entry *core.Entry) string {
+	switch entry.Kind {
+	case core.EntryKind_Directory:
+		return "directory"
+	case core.EntryKind_File:
+		return "file"
+	case core.EntryKind_SymbolicLink:
+		return "symlink"
+	}
+	return strings.ToLower(entry.Kind.String())
+}
+
+func describeChange(change *core.Change) string {
+	changePath := change.Path
+	if changePath == "" {
+		changePath = "."
+	}
+
+	switch {
+	case change.Old == nil && change.New != nil:
+		return fmt.Sprintf("%s: created %s", changePath, describeEntry(change.New))
+	case change.Old != nil && change.New == nil:
+		return fmt.Sprintf("%s: deleted %s", changePath, describeEntry(change.Old))
+	case change.Old != nil && change.New != nil && change.Old.Kind != change.New.Kind:
+		return fmt.Sprintf("%s: replaced %s with %s", changePath, describeEntry(change.Old), describeEntry(change.New))
+	}
+	return fmt.Sprintf("%s: modified %s", changePath, describeEntry(change.New))
+}
+
+func describeChanges(changes []*core.Change) []string {
+	descriptions := make([]string, len(changes))
+	for index, change := range changes {
+		descriptions[index] = describeChange(change)
+	}
+	return descriptions
+}
@@ -0,0 +1,170 @@
+package synchronization
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"os/exec"
+	"strings"
+	"sync"
+
+	"github.com/mutagen-io/mutagen/pkg/agent"
+	"github.com/mutagen-io/mutagen/pkg/agent/transport"
+	"github.com/mutagen-io/mutagen/pkg/logging"
+	"github.com/mutagen-io/mutagen/pkg/process"
+	"github.com/mutagen-io/mutagen/pkg/synchronization"
+	"github.com/mutagen-io/mutagen/pkg/synchronization/endpoint/remote"
+	"github.com/mutagen-io/mutagen/pkg/url"
+)
+
+const (
+	// the exec transport reuses the docker protocol of mutagen, which does not
+	// allow registering custom protocols
+	execProtocol = url.Protocol_Docker
+
+	kubeConfigParameter = "kube-config"
+	namespaceParameter  = "namespace"
+	// transportParameter marks the urls built for the exec transport
+	transportParameter = "workspace-transport"
+)
+
+// agentScript runs the agent command from the home folder as mutagen expects
+const agentScript = `cd && exec "$@"`
+
+// execTransport runs the mutagen agent via the exec api of kubernetes by
+// invoking workspace exec of the current executable
+type execTransport struct {
+	name           string
+	namespace      string
+	container      string
+	kubeConfigPath string
+}
+
+func (o *execTransport) command(stdin bool, script string, args ...string) (*exec.Cmd, error) {
+	executable, err := os.Executable()
+	if err != nil {
+		return nil, err
+	}
+
+	execArgs := []string{"exec", o.name, "--namespace", o.namespace, "--container", o.container}
+
+	if o.kubeConfigPath != "" {
+		execArgs = append(execArgs, "--kube-config", o.kubeConfigPath)
+	}
+
+	if stdin {
+		execArgs = append(execArgs, "--stdin")
+	}
+
+	execArgs = append(execArgs, "--", "sh", "-c", script, "workspace-agent")
+	execArgs = append(execArgs, args...)
+
+	command := exec.Command(executable, execArgs...)
+	command.SysProcAttr = transport.ProcessAttributes()
+
+	return command, nil
+}
+
+func (o *execTransport) Copy(localPath string, remoteName string) error {
+	file, err := os.Open(localPath)
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+
+	command, err := o.command(true, `cd && cat > "$1"`, remoteName)
+	if err != nil {
+		return err
+	}
+
+	var stderr bytes.Buffer
+	command.Stdin = file
+	command.Stderr = &stderr
+
+	if err := command.Run(); err != nil {
+		return fmt.Errorf("unable to copy agent: %s: %w", strings.TrimSpace(stderr.String()), err)
+	}
+
+	return nil
+}
+
+func (o *execTransport) Command(command string) (*exec.Cmd, error) {
+	return o.command(true, agentScript, strings.Split(command, " ")...)
+}
+
+func (o *execTransport) ClassifyError(processState *os.ProcessState, errorOutput string) (bool, bool, error) {
+	// the agent is (re-)installed if it is missing or not executable
+	if process.IsPOSIXShellCommandNotFound(processState) || process.IsPOSIXShellInvalidCommand(processState) {
+		return true, false, nil
+	}
+
+	return false, false, errors.New("unknown process exit error")
+}
+
+// execProtocolHandler connects to endpoints in workspaces via the exec transport
+type execProtocolHandler struct{}
+
+type dialResult struct {
+	stream io.ReadWriteCloser
+	err    error
+}
+
+func (o *execProtocolHandler) Connect(
+	ctx context.Context,
+	logger *logging.Logger,
+	url *url.URL,
+	prompter string,
+	session string,
+	version synchronization.Version,
+	configuration *synchronization.Configuration,
+	alpha bool,
+) (synchronization.Endpoint, error) {
+	if url.Parameters[transportParameter] != TransportExec {
+		return nil, fmt.Errorf("unsupported docker url %s, only the urls of the exec transport are supported", url.Format(""))
+	}
+
+	transport := &execTransport{
+		name:           url.Host,
+		namespace:      url.Parameters[namespaceParameter],
+		container:      url.User,
+		kubeConfigPath: url.Parameters[kubeConfigParameter],
+	}
+
+	results := make(chan dialResult)
+
+	// dial in the background to be able to react to cancellation
+	go func() {
+		stream, err := agent.Dial(logger, transport, agent.CommandSynchronizer, prompter)
+
+		select {
+		case results <- dialResult{stream, err}:
+		case <-ctx.Done():
+			if stream != nil {
+				stream.Close()
+			}
+		}
+	}()
+
+	select {
+	case result := <-results:
+		if result.err != nil {
+			return nil, fmt.Errorf("unable to dial agent endpoint: %w", result.err)
+		}
+		return remote.NewEndpoint(logger, result.stream, url.Path, session, version, configuration, alpha)
+	case <-ctx.Done():
+		return nil, context.Canceled
+	}
+}
+
+var registerExecProtocolOnce sync.Once
+
+// registerExecProtocol replaces the handler of the docker protocol with the
+// exec transport, it is only called if the exec transport is used
+func registerExecProtocol() {
+	registerExecProtocolOnce.Do(func() {
+		synchronization.ProtocolHandlers[execProtocol] = &execProtocolHandler{}
+	})
+}
@@ -5,10 +5,11 @@ import (
 	"errors"
 	"fmt"
 	"os"
+	"strings"
+	"sync"
 	"time"
 
 	"github.com/google/uuid"
-	"github.com/schollz/progressbar/v3"
 
 	"github.com/mutagen-io/mutagen/cmd/mutagen/common/templating"
 	"github.com/mutagen-io/mutagen/pkg/logging"
@@ -24,12 +25,30 @@ var monitorConfiguration struct {
 }
 
 type FileManager struct {
-	sessionName            string
-	progressBar            *progressbar.ProgressBar
+	name                   string
+	namespace              string
+	sessions               []*session
+	statusLock             sync.Mutex
+	ctx                    context.Context
+	cancel                 context.CancelFunc
+	monitorDone            chan struct{}
+	reporter               reporter
 	synchronizationManager *synchronization.Manager
 }
 
-func NewFileManager() (*FileManager, error) {
+// NewFileManager creates a file manager for the workspace. The mutagen state is
+// kept per workspace and sessions left over by a previous process are terminated.
+func NewFileManager(name string, namespace string, outputMode OutputMode) (*FileManager, error) {
+	dataFolder, err := getMutagenDataFolder(name, namespace)
+	if err != nil {
+		return nil, err
+	}
+
+	// mutagen reads its data directory from the environment
+	if err := os.Setenv("MUTAGEN_DATA_DIRECTORY", dataFolder); err != nil {
+		return nil, err
+	}
+
 	logging := logging.NewLogger(logging.LevelDisabled, os.Stderr)
 
 	manager, err := synchronization.NewManager(logging)
@@ -37,141 +56,329 @@ func NewFileManager() (*FileManager, error) {
 		return nil, err
 	}
 
+	if err := manager.Terminate(context.TODO(), &selection.Selection{All: true}, ""); err != nil {
+		manager.Shutdown()
+		return nil, fmt.Errorf("Failed to terminate previous sync sessions: %w", err)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+
 	return &FileManager{
+		name:                   name,
+		namespace:              namespace,
+		ctx:                    ctx,
+		cancel:                 cancel,
+		reporter:               newReporter(outputMode),
 		synchronizationManager: manager,
-		progressBar:            progressbar.Default(-1),
 	}, nil
 }
 
-func (o *FileManager) waitForSession() error {
-	ready := make(chan bool, 1)
-	failed := make(chan bool, 1)
+// selection selects all sessions of the workspace
+func (o *FileManager) selection() *selection.Selection {
+	return &selection.Selection{
+		LabelSelector: fmt.Sprintf("workspace-name=%s,workspace-namespace=%s", o.name, o.namespace),
+	}
+}
 
-	go func() {
-		var previousStateIndex uint64
-		previousStateIndex = 0
+// sessionReady checks if a session reached the state Run waits for. In watch
+// mode the session only needs to be connected, otherwise it must be idle and
+// waiting for changes. This does not mean that a synchronization cycle
+// completed, Run flushes the sessions afterwards to synchronize.
+func sessionReady(state *synchronization.State, watch bool) bool {
+	if watch {
+		return state.Status >= synchronization.Status_Watching
+	}
+	return state.Status == synchronization.Status_Watching
+}
 
-		for {
-			stateIndex, sessionStates, err := o.synchronizationManager.List(context.TODO(), &selection.Selection{
-				Specifications: []string{
-					o.sessionName,
-				},
-			}, uint64(previousStateIndex))
-
-			if err != nil {
-				failed <- true
-			} else {
-				previousStateIndex = stateIndex
-				if sessionStates[0].Status == synchronization.Status_Watching {
-					ready <- true
-				}
+func sessionHalted(state *synchronization.State) bool {
+	return state.Status == synchronization.Status_HaltedOnRootEmptied ||
+		state.Status == synchronization.Status_HaltedOnRootDeletion ||
+		state.Status == synchronization.Status_HaltedOnRootTypeChange
+}
+
+// describeFolder returns the sync folder of the session state
+func (o *FileManager) describeFolder(state *synchronization.State) string {
+	o.statusLock.Lock()
+	defer o.statusLock.Unlock()
+
+	for _, session := range o.sessions {
+		if session.identifier == state.Session.Identifier {
+			return session.folder.String()
+		}
+	}
+	return state.Session.Alpha.Path
+}
+
+// notReadyError reports the status and last error of all sessions which are not ready
+func (o *FileManager) notReadyError(states []*synchronization.State, watch bool, timeout time.Duration) error {
+	var messages []string
+
+	for _, state := range states {
+		if sessionReady(state, watch) {
+			continue
+		}
+
+		message := fmt.Sprintf("%s: %s", o.describeFolder(state), state.Status.Description())
+		if state.LastError != "" {
+			message += ": " + state.LastError
+		}
+		messages = append(messages, message)
+	}
+
+	return fmt.Errorf("Sync sessions not ready after %s (%s)", timeout, strings.Join(messages, ", "))
+}
+
+func (o *FileManager) waitForSessions(watch bool, timeout time.Duration) error {
+	ctx, cancel := context.WithTimeout(o.ctx, timeout)
+	defer cancel()
+
+	var previousStateIndex uint64
+	var sessionStates []*synchronization.State
+
+	for {
+		stateIndex, states, err := o.synchronizationManager.List(ctx, o.selection(), previousStateIndex)
+		if errors.Is(ctx.Err(), context.DeadlineExceeded) {
+			return o.notReadyError(sessionStates, watch, timeout)
+		} else if err != nil {
+			return fmt.Errorf("Failed to get status of sync sessions: %w", err)
+		}
+
+		previousStateIndex = stateIndex
+		sessionStates = states
+
+		ready := 0
+		for _, state := range states {
+			if sessionHalted(state) {
+				return fmt.Errorf("Sync session for %s halted: %s", o.describeFolder(state), state.Status.Description())
+			}
+
+			if sessionReady(state, watch) {
+				ready++
 			}
 		}
-	}()
 
-	select {
-	case <-ready:
-		return nil
-	case <-failed:
-		return fmt.Errorf("Failed to get status of session %s\n", o.sessionName)
-	case <-time.After(30 * time.Second):
-		return errors.New("timeout occured")
+		if ready == len(states) {
+			return nil
+		}
+	}
+}
+
+// buildLabels adds the workspace to the custom labels of a session
+func (o *FileManager) buildLabels(labels map[string]string) map[string]string {
+	sessionLabels := map[string]string{}
+	for key, value := range labels {
+		sessionLabels[key] = value
 	}
+
+	sessionLabels["workspace-name"] = o.name
+	sessionLabels["workspace-namespace"] = o.namespace
+
+	return sessionLabels
 }
 
-func (o *FileManager) createSession(source string, target Target, ignores []string, labels map[string]string, watch bool, syncMode string) error {
-	alpha, err := url.Parse(source, url.Kind_Synchronization, true)
+func (o *FileManager) createSession(folder SyncFolder, target Target, ignores []string, labels map[string]string, watch bool, config SessionConfig, timeout time.Duration) error {
+	alpha, err := url.Parse(folder.Source, url.Kind_Synchronization, true)
 	if err != nil {
 		return err
 	}
 
-	beta, err := url.Parse(target.buildUrl(), url.Kind_Synchronization, false)
+	target.Folder = folder.Target
+
+	beta, err := target.parseUrl()
 	if err != nil {
 		return err
 	}
 
-	configuration := &synchronization.Configuration{
-		Ignores:             ignores,
-		SynchronizationMode: getSyncMode(syncMode),
+	configuration, err := config.buildConfiguration(folder.Mode)
+	if err != nil {
+		return err
 	}
 
+	configuration.Ignores = folder.BuildIgnores(ignores)
+
 	if !watch {
 		configuration.WatchMode = synchronization.WatchMode_WatchModeNoWatch
 	}
 
-	configurationAlpha := &synchronization.Configuration{}
-	configurationBeta := &synchronization.Configuration{}
+	configurationAlpha, err := buildEndpointConfiguration("local", config.Local)
+	if err != nil {
+		return err
+	}
 
-	o.sessionName, err = o.synchronizationManager.Create(context.TODO(),
+	configurationBeta, err := buildEndpointConfiguration("remote", config.Remote)
+	if err != nil {
+		return err
+	}
+
+	// creating a session connects to both endpoints
+	ctx, cancel := context.WithTimeout(o.ctx, timeout)
+	defer cancel()
+
+	identifier, err := o.synchronizationManager.Create(ctx,
 		alpha,
 		beta,
 		configuration,
 		configurationAlpha,
 		configurationBeta,
 		uuid.NewString(),
-		labels,
+		o.buildLabels(labels),
 		false,
 		"")
 
-	return err
+	if err != nil {
+		return fmt.Errorf("Failed to create sync session for %s: %w", folder, err)
+	}
+
+	o.statusLock.Lock()
+	o.sessions = append(o.sessions, &session{
+		identifier: identifier,
+		folder:     folder,
+		localRoot:  alpha.Path,
+	})
+	o.statusLock.Unlock()
+
+	return nil
 }
 
-func (o *FileManager) logSession() {
+// monitorSessions keeps the status of all sessions up to date until the file
+// manager is stopped
+func (o *FileManager) monitorSessions() {
+	o.monitorDone = make(chan struct{})
+
 	go func() {
+		defer close(o.monitorDone)
+
 		var previousStateIndex uint64
-		previousStateIndex = 0
 
 		for {
-			stateIndex, sessionStates, err := o.synchronizationManager.List(context.TODO(), &selection.Selection{
-				Specifications: []string{
-					o.sessionName,
-				},
-			}, uint64(previousStateIndex))
-
-			if err == nil {
-				previousStateIndex = stateIndex
-				o.progressBar.Describe(sessionStates[0].Status.Description())
-				o.progressBar.Add(1)
-			} else {
-				fmt.Println(err.Error())
+			stateIndex, sessionStates, err := o.synchronizationManager.List(o.ctx, o.selection(), previousStateIndex)
+			if o.ctx.Err() != nil {
+				return
+			} else if err != nil {
+				fmt.Fprintf(os.Stderr, "Failed to get status of sync sessions: %s\n", err.Error())
 				return
 			}
+
+			previousStateIndex = stateIndex
+
+			o.statusLock.Lock()
+			for _, sessionState := range sessionStates {
+				for _, session := range o.sessions {
+					if session.identifier == sessionState.Session.Identifier {
+						for _, conflict := range session.update(sessionState) {
+							o.reporter.conflict(session, conflict.Root)
+						}
+					}
+				}
+			}
+			o.reporter.update(o.sessions)
+			o.statusLock.Unlock()
 		}
 	}()
 }
 
-func (o *FileManager) Run(source string, target Target, ignores []string, labels map[string]string, watch bool, syncMode string) error {
-	if err := o.createSession(source, target, ignores, labels, watch, syncMode); err != nil {
-		return err
+// Run creates a session per folder and waits until they are connected. If
+// watch is disabled it also waits for the synchronization to complete.
+func (o *FileManager) Run(folders []SyncFolder, target Target, ignores []string, labels map[string]string, watch bool, config SessionConfig, timeout time.Duration) error {
+	for _, folder := range folders {
+		if err := o.createSession(folder, target, ignores, labels, watch, config, timeout); err != nil {
+			return err
+		}
 	}
 
-	o.logSession()
+	o.monitorSessions()
+
+	if err := o.waitForSessions(watch, timeout); err != nil {
+		return err
+	}
 
-	// do not flush if watch mode is disabled
+	// do not flush if watch mode is enabled
 	if watch {
 		return nil
 	}
 
-	// wait for session to become active
-	if err := o.waitForSession(); err != nil {
-		return err
+	return o.synchronizationManager.Flush(o.ctx, o.selection(), "", false)
+}
+
+// Statuses returns a snapshot of all sessions
+func (o *FileManager) Statuses() []SessionStatus {
+	o.statusLock.Lock()
+	defer o.statusLock.Unlock()
+
+	statuses := make([]SessionStatus, len(o.sessions))
+	for index, session := range o.sessions {
+		statuses[index] = session.snapshot()
 	}
 
-	return o.synchronizationManager.Flush(context.TODO(), &selection.Selection{
-		Specifications: []string{
-			o.sessionName,
-		},
-	}, "", false)
+	return statuses
 }
 
+// Conflicts returns the conflicts of all sessions. Mutagen only reports the
+// first conflicts of each session, the rest shows up once those are resolved.
+func (o *FileManager) Conflicts() []Conflict {
+	o.statusLock.Lock()
+	defer o.statusLock.Unlock()
+
+	conflicts := []Conflict{}
+	for _, session := range o.sessions {
+		for _, conflict := range session.conflicts {
+			conflicts = append(conflicts, Conflict{
+				Session:       session.identifier,
+				Source:        session.folder.Source,
+				Target:        session.folder.Target,
+				LocalRoot:     session.localRoot,
+				Path:          conflict.Root,
+				LocalChanges:  describeChanges(conflict.AlphaChanges),
+				RemoteChanges: describeChanges(conflict.BetaChanges),
+			})
+		}
+	}
+
+	return conflicts
+}
+
+// Flush triggers a synchronization cycle without waiting for it to finish
+func (o *FileManager) Flush() error {
+	return o.synchronizationManager.Flush(o.ctx, o.selection(), "", true)
+}
+
+func (o *FileManager) Pause() error {
+	return o.synchronizationManager.Pause(o.ctx, o.selection(), "")
+}
+
+func (o *FileManager) Resume() error {
+	return o.synchronizationManager.Resume(o.ctx, o.selection(), "")
+}
+
+// Terminate terminates all sessions but keeps the manager running
+func (o *FileManager) Terminate() {
+	o.terminate(o.ctx)
+}
+
+func (o *FileManager) terminate(ctx context.Context) {
+	o.statusLock.Lock()
+	defer o.statusLock.Unlock()
+
+	if len(o.sessions) > 0 {
+		o.synchronizationManager.Terminate(ctx, o.selection(), "")
+	}
+
+	o.sessions = nil
+}
+
+// Stop stops monitoring, terminates all sessions and shuts down mutagen
 func (o *FileManager) Stop() {
-	if o.synchronizationManager != nil {
-		o.synchronizationManager.Terminate(context.TODO(), &selection.Selection{
-			Specifications: []string{
-				o.sessionName,
-			},
-		}, "")
+	if o.synchronizationManager == nil {
+		return
+	}
 
-		o.synchronizationManager.Shutdown()
+	o.cancel()
+	if o.monitorDone != nil {
+		<-o.monitorDone
 	}
+
+	o.terminate(context.Background())
+	o.reporter.finish()
+	o.synchronizationManager.Shutdown()
 }
@@ -0,0 +1,267 @@
+package synchronization
+
+import (
+	"bufio"
+	"fmt"
+	"io/fs"
+	"os"
+	pathpkg "path"
+	"path/filepath"
+	"strings"
+
+	"github.com/bmatcuk/doublestar/v4"
+	"github.com/mutagen-io/mutagen/pkg/synchronization/core"
+)
+
+// IgnoreFileNames are read in every synchronized directory, rules of later
+// files take precedence
+var IgnoreFileNames = []string{".gitignore", ".workspaceignore"}
+
+// ignorePattern mirrors the matching of mutagen ignore patterns, which mutagen
+// does not export
+type ignorePattern struct {
+	negated       bool
+	directoryOnly bool
+	matchLeaf     bool
+	pattern       string
+}
+
+func newIgnorePattern(pattern string) (*ignorePattern, error) {
+	if !core.ValidIgnorePattern(pattern) {
+		return nil, fmt.Errorf("Invalid ignore pattern %s", pattern)
+	}
+
+	negated := strings.HasPrefix(pattern, "!")
+	pattern = strings.TrimPrefix(pattern, "!")
+
+	absolute := strings.HasPrefix(pattern, "/")
+	pattern = strings.TrimPrefix(pattern, "/")
+
+	directoryOnly := strings.HasSuffix(pattern, "/")
+	pattern = strings.TrimSuffix(pattern, "/")
+
+	return &ignorePattern{
+		negated:       negated,
+		directoryOnly: directoryOnly,
+		matchLeaf:     !absolute && !strings.Contains(pattern, "/"),
+		pattern:       pattern,
+	}, nil
+}
+
+func (o *ignorePattern) matches(path string, directory bool) bool {
+	if o.directoryOnly && !directory {
+		return false
+	}
+
+	if match, _ := doublestar.Match(o.pattern, path); match {
+		return true
+	}
+
+	if o.matchLeaf {
+		match, _ := doublestar.Match(o.pattern, pathpkg.Base(path))
+		return match
+	}
+
+	return false
+}
+
+// Ignorer decides which paths mutagen ignores for a list of patterns, the last
+// matching pattern wins
+type Ignorer struct {
+	patterns []*ignorePattern
+}
+
+func NewIgnorer(patterns []string) (*Ignorer, error) {
+	ignorer := &Ignorer{}
+
+	for _, pattern := range patterns {
+		ignorePattern, err := newIgnorePattern(pattern)
+		if err != nil {
+			return nil, err
+		}
+		ignorer.patterns = append(ignorer.patterns, ignorePattern)
+	}
+
+	return ignorer, nil
+}
+
+// Ignored checks a slash separated path relative to the synchronization root
+func (o *Ignorer) Ignored(path string, directory bool) bool {
+	ignored := false
+
+	for _, pattern := range o.patterns {
+		if pattern.matches(path, directory) {
+			ignored = !pattern.negated
+		}
+	}
+
+	return ignored
+}
+
+// convertGitignorePattern converts a line of an ignore file in the directory
+// (relative to the synchronization root) to a mutagen pattern. An empty
+// pattern is returned for blank lines and comments.
+func convertGitignorePattern(directory string, line string) string {
+	// trailing spaces are ignored unless they are escaped
+	line = strings.TrimRight(line, "\r")
+	for strings.HasSuffix(line, " ") && !strings.HasSuffix(line, "\\ ") {
+		line = line[:len(line)-1]
+	}
+
+	if line == "" || strings.HasPrefix(line, "#") {
+		return ""
+	}
+
+	negation := ""
+	switch {
+	case strings.HasPrefix(line, "!"):
+		negation = "!"
+		line = line[1:]
+	case strings.HasPrefix(line, "\\#"), strings.HasPrefix(line, "\\!"):
+		// an escaped leading # or ! is part of the name
+		line = line[1:]
+	}
+
+	// a slash at the beginning or in the middle anchors the pattern to the
+	// directory of the ignore file, otherwise it matches at any depth below it
+	anchored := strings.Contains(strings.TrimSuffix(line, "/"), "/")
+	line = strings.TrimPrefix(line, "/")
+
+	if line == "" || line == "/" {
+		return ""
+	}
+
+	switch {
+	case directory == "" && anchored:
+		return negation + "/" + line
+	case directory == "" && strings.HasPrefix(line, "!"):
+		// mutagen would treat a leading ! as negation
+		return negation + "\\" + line
+	case directory == "":
+		return negation + line
+	case anchored:
+		return negation + "/" + directory + "/" + line
+	}
+
+	return negation + "/" + directory + "/**/" + line
+}
+
+func readIgnoreFile(path string, directory string) ([]string, error) {
+	file, err := os.Open(path)
+	if os.IsNotExist(err) {
+		return nil, nil
+	} else if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+
+	var patterns []string
+
+	scanner := bufio.NewScanner(file)
+	for scanner.Scan() {
+		pattern := convertGitignorePattern(directory, scanner.Text())
+		if pattern == "" {
+			continue
+		}
+
+		if !core.ValidIgnorePattern(pattern) {
+			return nil, fmt.Errorf("Invalid ignore pattern %s in %s", scanner.Text(), path)
+		}
+
+		patterns = append(patterns, pattern)
+	}
+
+	return patterns, scanner.Err()
+}
+
+// LoadIgnoreFiles reads the ignore files of the root and all its directories
+// which are not ignored. Like git, ignore files in ignored directories are not
+// read. The ignores are applied after the loaded patterns.
+func LoadIgnoreFiles(root string, ignores []string) ([]string, error) {
+	var patterns []string
+
+	err := filepath.WalkDir(root, func(path string, entry fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+
+		if !entry.IsDir() {
+			return nil
+		}
+
+		relativePath, err := filepath.Rel(root, path)
+		if err != nil {
+			return err
+		}
+
+		directory := filepath.ToSlash(relativePath)
+		if directory == "." {
+			directory = ""
+		}
+
+		if directory != "" {
+			ignorer, err := NewIgnorer(append(append([]string{}, patterns...), ignores...))
+			if err != nil {
+				return err
+			}
+
+			if ignorer.Ignored(directory, true) {
+				return filepath.SkipDir
+			}
+		}
+
+		for _, name := range IgnoreFileNames {
+			filePatterns, err := readIgnoreFile(filepath.Join(path, name), directory)
+			if err != nil {
+				return err
+			}
+			patterns = append(patterns, filePatterns...)
+		}
+
+		return nil
+	})
+
+	return patterns, err
+}
+
+// ListIgnored returns the paths below the root which are ignored, the content
+// of ignored directories is not listed
+func ListIgnored(root string, patterns []string) ([]string, error) {
+	ignorer, err := NewIgnorer(patterns)
+	if err != nil {
+		return nil, err
+	}
+
+	var ignored []string
+
+	err = filepath.WalkDir(root, func(path string, entry fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+
+		relativePath, err := filepath.Rel(root, path)
+		if err != nil {
+			return err
+		}
+
+		if relativePath == "." {
+			return nil
+		}
+
+		relativePath = filepath.ToSlash(relativePath)
+
+		if !ignorer.Ignored(relativePath, entry.IsDir()) {
+			return nil
+		}
+
+		if entry.IsDir() {
+			ignored = append(ignored, relativePath+"/")
+			return filepath.SkipDir
+		}
+
+		ignored = append(ignored, relativePath)
+		return nil
+	})
+
+	return ignored, err
+}
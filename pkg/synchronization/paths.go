@@ -0,0 +1,57 @@
+package synchronization
+
+import (
+	"os"
+	"path/filepath"
+
+	"github.com/salberternst/workspace/pkg/utils"
+)
+
+const (
+	syncFolderName    = "sync"
+	socketFileName    = "sync.sock"
+	logFileName       = "sync.log"
+	mutagenFolderName = "mutagen"
+)
+
+// getSyncFolder returns the folder holding the synchronization state of a workspace
+func getSyncFolder(name string, namespace string) (string, error) {
+	configFolder, err := utils.GetConfigFolder(name, namespace)
+	if err != nil {
+		return "", err
+	}
+
+	syncFolder := filepath.Join(configFolder, syncFolderName)
+
+	return syncFolder, os.MkdirAll(syncFolder, 0700)
+}
+
+func getSocketPath(name string, namespace string) (string, error) {
+	syncFolder, err := getSyncFolder(name, namespace)
+	if err != nil {
+		return "", err
+	}
+
+	return filepath.Join(syncFolder, socketFileName), nil
+}
+
+// GetLogPath returns the log file of the background synchronization
+func GetLogPath(name string, namespace string) (string, error) {
+	syncFolder, err := getSyncFolder(name, namespace)
+	if err != nil {
+		return "", err
+	}
+
+	return filepath.Join(syncFolder, logFileName), nil
+}
+
+// getMutagenDataFolder returns a mutagen data directory per workspace, so that
+// mutagen only loads the sessions belonging to the workspace
+func getMutagenDataFolder(name string, namespace string) (string, error) {
+	syncFolder, err := getSyncFolder(name, namespace)
+	if err != nil {
+		return "", err
+	}
+
+	return filepath.Join(syncFolder, mutagenFolderName), nil
+}
@@ -0,0 +1,233 @@
+package synchronization
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/dustin/go-humanize"
+	"github.com/fatih/color"
+	"github.com/schollz/progressbar/v3"
+	"golang.org/x/term"
+)
+
+// OutputMode selects how the file manager reports the sync progress
+type OutputMode int
+
+const (
+	// OutputProgress renders a progress bar while files are staged and a
+	// status line otherwise
+	OutputProgress OutputMode = iota
+	// OutputQuiet only prints warnings
+	OutputQuiet
+	// OutputJsonEvents prints a json object per line for every change
+	OutputJsonEvents
+)
+
+type reporter interface {
+	update(sessions []*session)
+	conflict(session *session, path string)
+	finish()
+}
+
+func newReporter(mode OutputMode) reporter {
+	switch mode {
+	case OutputQuiet:
+		return &quietReporter{}
+	case OutputJsonEvents:
+		return &jsonReporter{
+			encoder:  json.NewEncoder(os.Stdout),
+			previous: map[string]SessionStatus{},
+		}
+	}
+	return &progressReporter{}
+}
+
+func conflictPath(path string) string {
+	if path == "" {
+		return "."
+	}
+	return path
+}
+
+func warnConflict(session *session, path string) {
+	fmt.Fprintf(os.Stderr, "\r\n%s\r\n", color.YellowString("Warning: conflict at %s in %s, see workspace sync conflicts", conflictPath(path), session.folder))
+}
+
+type quietReporter struct{}
+
+func (o *quietReporter) update(sessions []*session) {}
+
+func (o *quietReporter) conflict(session *session, path string) {
+	warnConflict(session, path)
+}
+
+func (o *quietReporter) finish() {}
+
+// progressReporter renders a determinate progress bar while files are staged
+// and a compact status line otherwise
+type progressReporter struct {
+	bar           *progressbar.ProgressBar
+	expectedFiles uint64
+	statusLine    string
+}
+
+func describeSession(session *session) string {
+	description := session.folder.Source + ": " + session.status.Description()
+	if session.paused {
+		description = session.folder.Source + ": Paused"
+	}
+
+	if session.files > 0 {
+		description += fmt.Sprintf(", %s files, %s", humanize.Comma(int64(session.files)), humanize.Bytes(session.totalFileSize))
+	}
+
+	if session.transferredBytes > 0 {
+		description += fmt.Sprintf(", %s transferred", humanize.Bytes(session.transferredBytes))
+	}
+
+	if session.totalConflicts > 0 {
+		description += fmt.Sprintf(", %d conflicts", session.totalConflicts)
+	}
+
+	if session.lastError != "" {
+		description += ", error: " + session.lastError
+	}
+
+	return description
+}
+
+func (o *progressReporter) update(sessions []*session) {
+	var stagedBytes, stagedFiles, expectedFiles uint64
+	for _, session := range sessions {
+		stagedBytes += session.stagedBytes
+		stagedFiles += session.stagedFiles
+		expectedFiles += session.expectedFiles
+	}
+
+	if expectedFiles > 0 {
+		if o.bar == nil || o.expectedFiles != expectedFiles {
+			o.clearStatusLine()
+			o.bar = progressbar.NewOptions64(int64(expectedFiles),
+				progressbar.OptionSetWriter(os.Stderr),
+				progressbar.OptionShowCount(),
+				progressbar.OptionSetWidth(20),
+				progressbar.OptionClearOnFinish(),
+				progressbar.OptionThrottle(65*time.Millisecond),
+			)
+			o.expectedFiles = expectedFiles
+		}
+
+		o.bar.Describe(fmt.Sprintf("Staging files (%s)", humanize.Bytes(stagedBytes)))
+		o.bar.Set64(int64(stagedFiles))
+		return
+	}
+
+	o.finishBar()
+
+	descriptions := make([]string, len(sessions))
+	for index, session := range sessions {
+		descriptions[index] = describeSession(session)
+	}
+
+	o.printStatusLine(strings.Join(descriptions, " | "))
+}
+
+func (o *progressReporter) finishBar() {
+	if o.bar != nil {
+		o.bar.Finish()
+		o.bar = nil
+		o.expectedFiles = 0
+	}
+}
+
+func stderrIsTerminal() bool {
+	return term.IsTerminal(int(os.Stderr.Fd()))
+}
+
+// printStatusLine replaces the current line, the line is truncated to the
+// width of the terminal so that it does not wrap. Without a terminal every
+// changed status is printed as a separate line.
+func (o *progressReporter) printStatusLine(line string) {
+	terminal := stderrIsTerminal()
+
+	if width, _, err := term.GetSize(int(os.Stderr.Fd())); terminal && err == nil && width > 1 {
+		if runes := []rune(line); len(runes) >= width {
+			line = string(runes[:width-1])
+		}
+	}
+
+	if line == o.statusLine {
+		return
+	}
+
+	o.statusLine = line
+
+	if !terminal {
+		fmt.Fprintln(os.Stderr, line)
+		return
+	}
+
+	fmt.Fprintf(os.Stderr, "\r\033[K%s", line)
+}
+
+func (o *progressReporter) clearStatusLine() {
+	if o.statusLine != "" && stderrIsTerminal() {
+		fmt.Fprint(os.Stderr, "\r\033[K")
+	}
+	o.statusLine = ""
+}
+
+func (o *progressReporter) conflict(session *session, path string) {
+	o.clearStatusLine()
+	warnConflict(session, path)
+}
+
+func (o *progressReporter) finish() {
+	o.finishBar()
+	o.clearStatusLine()
+}
+
+// Event is printed as json for every change of a session
+type Event struct {
+	Time time.Time `json:"time"`
+	// Type is either status or conflict
+	Type string `json:"type"`
+	// Path is the path of a conflict relative to the source
+	Path string `json:"path,omitempty"`
+	SessionStatus
+}
+
+type jsonReporter struct {
+	encoder  *json.Encoder
+	previous map[string]SessionStatus
+}
+
+func (o *jsonReporter) update(sessions []*session) {
+	for _, session := range sessions {
+		status := session.snapshot()
+		if previous, ok := o.previous[session.identifier]; ok && previous == status {
+			continue
+		}
+		o.previous[session.identifier] = status
+
+		o.encoder.Encode(Event{
+			Time:          time.Now(),
+			Type:          "status",
+			SessionStatus: status,
+		})
+	}
+}
+
+func (o *jsonReporter) conflict(session *session, path string) {
+	o.encoder.Encode(Event{
+		Time:          time.Now(),
+		Type:          "conflict",
+		Path:          conflictPath(path),
+		SessionStatus: session.snapshot(),
+	})
+}
+
+func (o *jsonReporter) finish() {}
@@ -0,0 +1,115 @@
+package synchronization
+
+import (
+	"fmt"
+	"net"
+	"net/rpc"
+	"os"
+	"sync"
+)
+
+const controlServiceName = "Sync"
+
+// Empty is used for control requests and replies without data
+type Empty struct{}
+
+// ControlServer exposes the sessions of a file manager to other processes via
+// a unix socket per workspace. The socket also makes sure that only a single
+// process synchronizes a workspace at a time.
+type ControlServer struct {
+	listener net.Listener
+	done     chan struct{}
+	doneOnce sync.Once
+}
+
+// Listen acquires the control socket of the workspace
+func Listen(name string, namespace string) (*ControlServer, error) {
+	if client, err := Dial(name, namespace); err == nil {
+		client.Close()
+		return nil, fmt.Errorf("Synchronization of workspace %s in namespace %s is already running", name, namespace)
+	}
+
+	socketPath, err := getSocketPath(name, namespace)
+	if err != nil {
+		return nil, err
+	}
+
+	// remove the socket of a process which did not shut down cleanly
+	if err := os.Remove(socketPath); err != nil && !os.IsNotExist(err) {
+		return nil, err
+	}
+
+	listener, err := net.Listen("unix", socketPath)
+	if err != nil {
+		return nil, err
+	}
+
+	return &ControlServer{
+		listener: listener,
+		done:     make(chan struct{}),
+	}, nil
+}
+
+// Serve starts accepting control requests for the file manager
+func (o *ControlServer) Serve(fileManager *FileManager) error {
+	server := rpc.NewServer()
+
+	if err := server.RegisterName(controlServiceName, &ControlService{
+		fileManager: fileManager,
+		server:      o,
+	}); err != nil {
+		return err
+	}
+
+	go server.Accept(o.listener)
+
+	return nil
+}
+
+// Done is closed once a stop request was received
+func (o *ControlServer) Done() <-chan struct{} {
+	return o.done
+}
+
+func (o *ControlServer) Close() error {
+	return o.listener.Close()
+}
+
+func (o *ControlServer) stop() {
+	o.doneOnce.Do(func() {
+		close(o.done)
+	})
+}
+
+type ControlService struct {
+	fileManager *FileManager
+	server      *ControlServer
+}
+
+func (o *ControlService) Status(args *Empty, reply *[]SessionStatus) error {
+	*reply = o.fileManager.Statuses()
+	return nil
+}
+
+func (o *ControlService) Conflicts(args *Empty, reply *[]Conflict) error {
+	*reply = o.fileManager.Conflicts()
+	return nil
+}
+
+func (o *ControlService) Flush(args *Empty, reply *Empty) error {
+	return o.fileManager.Flush()
+}
+
+func (o *ControlService) Pause(args *Empty, reply *Empty) error {
+	return o.fileManager.Pause()
+}
+
+func (o *ControlService) Resume(args *Empty, reply *Empty) error {
+	return o.fileManager.Resume()
+}
+
+func (o *ControlService) Stop(args *Empty, reply *Empty) error {
+	o.fileManager.Terminate()
+	o.server.stop()
+	return nil
+}
@@ -0,0 +1,111 @@
+package synchronization
+
+import (
+	"time"
+
+	"github.com/mutagen-io/mutagen/pkg/synchronization"
+	"github.com/mutagen-io/mutagen/pkg/synchronization/core"
+)
+
+// session is a single mutagen session created for a sync folder
+type session struct {
+	identifier       string
+	folder           SyncFolder
+	localRoot        string
+	status           synchronization.Status
+	paused           bool
+	lastError        string
+	conflicts        []*core.Conflict
+	totalConflicts   int
+	files            uint64
+	directories      uint64
+	totalFileSize    uint64
+	cycles           uint64
+	stagedBytes      uint64
+	stagedFiles      uint64
+	expectedFiles    uint64
+	transferredBytes uint64
+	scanStartedAt    time.Time
+	scanDuration     time.Duration
+}
+
+// newConflicts returns the conflicts which were not reported before
+func (o *session) newConflicts(conflicts []*core.Conflict) []*core.Conflict {
+	known := map[string]bool{}
+	for _, conflict := range o.conflicts {
+		known[conflict.Root] = true
+	}
+
+	var added []*core.Conflict
+	for _, conflict := range conflicts {
+		if !known[conflict.Root] {
+			added = append(added, conflict)
+		}
+	}
+	return added
+}
+
+// update applies the state and returns the new conflicts
+func (o *session) update(state *synchronization.State) []*core.Conflict {
+	added := o.newConflicts(state.Conflicts)
+
+	// the scan duration is measured from the first to the last scanning state
+	if state.Status == synchronization.Status_Scanning && o.status != synchronization.Status_Scanning {
+		o.scanStartedAt = time.Now()
+	} else if state.Status != synchronization.Status_Scanning && o.status == synchronization.Status_Scanning {
+		o.scanDuration = time.Since(o.scanStartedAt)
+	}
+
+	o.status = state.Status
+	o.paused = state.Session.Paused
+	o.lastError = state.LastError
+	o.conflicts = state.Conflicts
+	o.totalConflicts = len(state.Conflicts) + int(state.ExcludedConflicts)
+	o.cycles = state.SuccessfulCycles
+
+	if state.AlphaState != nil {
+		o.files = state.AlphaState.Files
+		o.directories = state.AlphaState.Directories
+		o.totalFileSize = state.AlphaState.TotalFileSize
+	}
+
+	var staged, stagedFiles, expectedFiles uint64
+	for _, endpointState := range []*synchronization.EndpointState{state.AlphaState, state.BetaState} {
+		if endpointState != nil && endpointState.StagingProgress != nil {
+			staged += endpointState.StagingProgress.TotalReceivedSize
+			stagedFiles += endpointState.StagingProgress.ReceivedFiles
+			expectedFiles += endpointState.StagingProgress.ExpectedFiles
+		}
+	}
+
+	// a staging cycle ended once the staged bytes drop
+	if staged < o.stagedBytes {
+		o.transferredBytes += o.stagedBytes
+	}
+	o.stagedBytes = staged
+	o.stagedFiles = stagedFiles
+	o.expectedFiles = expectedFiles
+
+	return added
+}
+
+func (o *session) snapshot() SessionStatus {
+	return SessionStatus{
+		Identifier:       o.identifier,
+		Source:           o.folder.Source,
+		Target:           o.folder.Target,
+		Status:           o.status.Description(),
+		Paused:           o.paused,
+		Files:            o.files,
+		Directories:      o.directories,
+		TotalFileSize:    o.totalFileSize,
+		Cycles:           o.cycles,
+		ScanDurationMs:   o.scanDuration.Milliseconds(),
+		StagedBytes:      o.stagedBytes,
+		StagedFiles:      o.stagedFiles,
+		ExpectedFiles:    o.expectedFiles,
+		TransferredBytes: o.transferredBytes,
+		Conflicts:        o.totalConflicts,
+		LastError:        o.lastError,
+	}
+}
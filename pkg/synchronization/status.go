@@ -0,0 +1,21 @@
+package synchronization
+
+// SessionStatus is a snapshot of a sync session as reported to other processes
+type SessionStatus struct {
+	Identifier       string `json:"identifier"`
+	Source           string `json:"source"`
+	Target           string `json:"target"`
+	Status           string `json:"status"`
+	Paused           bool   `json:"paused"`
+	Files            uint64 `json:"files"`
+	Directories      uint64 `json:"directories"`
+	TotalFileSize    uint64 `json:"totalFileSize"`
+	Cycles           uint64 `json:"cycles"`
+	ScanDurationMs   int64  `json:"scanDurationMs"`
+	StagedBytes      uint64 `json:"stagedBytes"`
+	StagedFiles      uint64 `json:"stagedFiles"`
+	ExpectedFiles    uint64 `json:"expectedFiles"`
+	TransferredBytes uint64 `json:"transferredBytes"`
+	Conflicts        int    `json:"conflicts"`
+	LastError        string `json:"lastError,omitempty"`
+}
@@ -0,0 +1,78 @@
+package synchronization
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/mutagen-io/mutagen/pkg/filesystem"
+)
+
+// SyncFolder describes a single folder synchronized to the workspace in the
+// form of source:target[,mode=<mode>][,ignore=<pattern>]...
+type SyncFolder struct {
+	Source  string
+	Target  string
+	Mode    string
+	Ignores []string
+	// FileIgnores are loaded from the ignore files of the source
+	FileIgnores []string
+}
+
+func ParseSyncFolder(value string) (SyncFolder, error) {
+	options := strings.Split(value, ",")
+
+	// the target is a path inside the workspace, so the last colon separates
+	// it from the source which might contain a drive letter on windows
+	separator := strings.LastIndex(options[0], ":")
+	if separator <= 0 || separator == len(options[0])-1 {
+		return SyncFolder{}, fmt.Errorf("Invalid sync folder %s, expected source:target", value)
+	}
+
+	folder := SyncFolder{
+		Source:  options[0][:separator],
+		Target:  options[0][separator+1:],
+		Ignores: []string{},
+	}
+
+	for _, option := range options[1:] {
+		key, optionValue, found := strings.Cut(option, "=")
+		if !found || optionValue == "" {
+			return SyncFolder{}, fmt.Errorf("Invalid option %s in sync folder %s, expected key=value", option, value)
+		}
+
+		switch key {
+		case "mode":
+			if err := ValidateSyncMode(optionValue); err != nil {
+				return SyncFolder{}, fmt.Errorf("%s in sync folder %s", err.Error(), value)
+			}
+			folder.Mode = optionValue
+		case "ignore":
+			folder.Ignores = append(folder.Ignores, optionValue)
+		default:
+			return SyncFolder{}, fmt.Errorf("Unknown option %s in sync folder %s", key, value)
+		}
+	}
+
+	return folder, nil
+}
+
+// LoadIgnoreFiles loads the rules of the .gitignore and .workspaceignore files
+// of the source, the ignores are used to skip ignored directories
+func (o *SyncFolder) LoadIgnoreFiles(ignores []string) error {
+	source, err := filesystem.Normalize(o.Source)
+	if err != nil {
+		return err
+	}
+
+	o.FileIgnores, err = LoadIgnoreFiles(source, append(append([]string{}, ignores...), o.Ignores...))
+	return err
+}
+
+// BuildIgnores returns all ignores of the folder, later ones take precedence
+func (o *SyncFolder) BuildIgnores(ignores []string) []string {
+	return append(append(append([]string{}, o.FileIgnores...), ignores...), o.Ignores...)
+}
+
+func (o SyncFolder) String() string {
+	return o.Source + ":" + o.Target
+}
@@ -1,14 +1,67 @@
 package synchronization
 
-import "strconv"
+import (
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/mutagen-io/mutagen/pkg/url"
+)
+
+const (
+	TransportSsh  = "ssh"
+	TransportExec = "exec"
+)
+
+var Transports = []string{TransportSsh, TransportExec}
 
 type Target struct {
-	Port     uint16
-	Hostname string
-	Folder   string
-	Username string
+	Transport string
+	Port      uint16
+	Hostname  string
+	Folder    string
+	Username  string
+	// used by the exec transport
+	Name           string
+	Namespace      string
+	Container      string
+	KubeConfigPath string
 }
 
 func (o *Target) buildUrl() string {
 	return o.Username + "@" + o.Hostname + ":" + strconv.Itoa(int(o.Port)) + ":" + o.Folder
 }
+
+func (o *Target) buildExecUrl() *url.URL {
+	// relative folders are relative to the home folder like with ssh
+	folder := o.Folder
+	if !strings.HasPrefix(folder, "/") && !strings.HasPrefix(folder, "~") {
+		folder = "~/" + folder
+	}
+
+	return &url.URL{
+		Kind:     url.Kind_Synchronization,
+		Protocol: execProtocol,
+		User:     o.Container,
+		Host:     o.Name,
+		Path:     folder,
+		Parameters: map[string]string{
+			namespaceParameter:  o.Namespace,
+			kubeConfigParameter: o.KubeConfigPath,
+			transportParameter:  TransportExec,
+		},
+	}
+}
+
+// parseUrl returns the mutagen url of the target for the transport
+func (o *Target) parseUrl() (*url.URL, error) {
+	switch o.Transport {
+	case TransportExec:
+		registerExecProtocol()
+		return o.buildExecUrl(), nil
+	case TransportSsh, "":
+		return url.Parse(o.buildUrl(), url.Kind_Synchronization, false)
+	}
+
+	return nil, fmt.Errorf("Invalid sync transport %s, allowed values are %s", o.Transport, strings.Join(Transports, ", "))
+}
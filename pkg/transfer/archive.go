@@ -0,0 +1,349 @@
+package transfer
+
+import (
+	"archive/tar"
+	"compress/gzip"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// compressReader compresses the reader with gzip, the returned reader must be
+// closed to stop compressing
+func compressReader(reader io.Reader) io.ReadCloser {
+	pipeReader, pipeWriter := io.Pipe()
+
+	go func() {
+		writer := gzip.NewWriter(pipeWriter)
+
+		_, err := io.Copy(writer, reader)
+		if err == nil {
+			err = writer.Close()
+		}
+
+		pipeWriter.CloseWithError(err)
+	}()
+
+	return pipeReader
+}
+
+// localFiles returns the total size of all regular files in the directory and
+// the files larger than the threshold relative to the directory
+func localFiles(root string, threshold int64) (int64, map[string]int64, error) {
+	var total int64
+	largeFiles := map[string]int64{}
+
+	err := filepath.WalkDir(root, func(path string, entry fs.DirEntry, err error) error {
+		if err != nil || !entry.Type().IsRegular() {
+			return err
+		}
+
+		info, err := entry.Info()
+		if err != nil {
+			return err
+		}
+
+		total += info.Size()
+
+		if info.Size() > threshold {
+			relativePath, err := filepath.Rel(root, path)
+			if err != nil {
+				return err
+			}
+			largeFiles[filepath.ToSlash(relativePath)] = info.Size()
+		}
+
+		return nil
+	})
+
+	return total, largeFiles, err
+}
+
+// writeTar writes the directory without the skipped files as tar, the content
+// of the files is also written to progress
+func writeTar(writer io.Writer, root string, skip map[string]int64, progress io.Writer) error {
+	tarWriter := tar.NewWriter(writer)
+
+	err := filepath.WalkDir(root, func(path string, entry fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+
+		relativePath, err := filepath.Rel(root, path)
+		if err != nil || relativePath == "." {
+			return err
+		}
+
+		name := filepath.ToSlash(relativePath)
+		if _, ok := skip[name]; ok {
+			return nil
+		}
+
+		info, err := entry.Info()
+		if err != nil {
+			return err
+		}
+
+		link := ""
+		switch {
+		case info.Mode()&os.ModeSymlink != 0:
+			if link, err = os.Readlink(path); err != nil {
+				return err
+			}
+		case !info.IsDir() && !info.Mode().IsRegular():
+			// sockets, devices and pipes can not be transferred
+			return nil
+		}
+
+		header, err := tar.FileInfoHeader(info, link)
+		if err != nil {
+			return err
+		}
+
+		header.Name = name
+		if info.IsDir() {
+			header.Name += "/"
+		}
+
+		if err := tarWriter.WriteHeader(header); err != nil {
+			return err
+		}
+
+		if !info.Mode().IsRegular() {
+			return nil
+		}
+
+		file, err := os.Open(path)
+		if err != nil {
+			return err
+		}
+		defer file.Close()
+
+		_, err = io.Copy(tarWriter, io.TeeReader(file, progress))
+		return err
+	})
+
+	if err != nil {
+		return err
+	}
+
+	return tarWriter.Close()
+}
+
+// insideRoot checks that the path does not leave the root via symlinks
+func insideRoot(root string, path string) (bool, error) {
+	resolvedPath, err := filepath.EvalSymlinks(path)
+	if err != nil {
+		return false, err
+	}
+
+	return lexicallyInsideRoot(root, resolvedPath)
+}
+
+// lexicallyInsideRoot checks that the cleaned path is the root or below it
+// without resolving symlinks
+func lexicallyInsideRoot(root string, path string) (bool, error) {
+	relativePath, err := filepath.Rel(root, path)
+	if err != nil {
+		return false, err
+	}
+
+	return relativePath != ".." && !strings.HasPrefix(relativePath, ".."+string(filepath.Separator)), nil
+}
+
+// mkdirInsideRoot creates the directory relative to the root one component at
+// a time with the mode. Existing symlinks are only followed if they stay inside
+// the root, nothing is created before that was checked.
+func mkdirInsideRoot(root string, directory string, mode os.FileMode) error {
+	path := root
+
+	for _, component := range strings.Split(directory, string(filepath.Separator)) {
+		if component == "." {
+			continue
+		}
+
+		path = filepath.Join(path, component)
+
+		info, err := os.Lstat(path)
+		if os.IsNotExist(err) {
+			if err := os.Mkdir(path, mode); err != nil {
+				return err
+			}
+			continue
+		} else if err != nil {
+			return err
+		}
+
+		if info.Mode()&os.ModeSymlink == 0 {
+			continue
+		}
+
+		if inside, err := insideRoot(root, path); err != nil {
+			return err
+		} else if !inside {
+			return fmt.Errorf("Invalid path %s in archive, %s leaves the destination", directory, path)
+		}
+	}
+
+	return nil
+}
+
+// archivePath cleans the name of an entry and rejects absolute names and
+// names leaving the root
+func archivePath(name string) (string, error) {
+	path := filepath.Clean(filepath.FromSlash(name))
+
+	if filepath.IsAbs(path) || path == ".." || strings.HasPrefix(path, ".."+string(filepath.Separator)) {
+		return "", fmt.Errorf("Invalid path %s in archive", name)
+	}
+
+	return path, nil
+}
+
+// removeExisting removes an existing file or symlink at the path so that it is
+// replaced instead of written through
+func removeExisting(path string) error {
+	info, err := os.Lstat(path)
+	if os.IsNotExist(err) {
+		return nil
+	} else if err != nil {
+		return err
+	}
+
+	if info.IsDir() {
+		return fmt.Errorf("%s is an existing directory", path)
+	}
+
+	return os.Remove(path)
+}
+
+// extractTar extracts the tar into the directory, entries outside of the
+// directory, also via symlinks and hard links, are rejected
+func extractTar(reader io.Reader, root string, progress io.Writer) error {
+	tarReader := tar.NewReader(reader)
+
+	root, err := filepath.EvalSymlinks(root)
+	if err != nil {
+		return err
+	}
+
+	for {
+		header, err := tarReader.Next()
+		if err == io.EOF {
+			return nil
+		} else if err != nil {
+			return err
+		}
+
+		name, err := archivePath(header.Name)
+		if err != nil {
+			return err
+		} else if name == "." {
+			continue
+		}
+
+		path := filepath.Join(root, name)
+		mode := os.FileMode(header.Mode).Perm()
+
+		if err := mkdirInsideRoot(root, filepath.Dir(name), 0755); err != nil {
+			return err
+		}
+
+		switch header.Typeflag {
+		case tar.TypeDir:
+			if err := mkdirInsideRoot(root, name, mode); err != nil {
+				return err
+			}
+		case tar.TypeReg:
+			if err := removeExisting(path); err != nil {
+				return err
+			}
+			if err := extractFile(tarReader, path, mode, progress); err != nil {
+				return err
+			}
+		case tar.TypeSymlink:
+			if err := extractSymlink(root, path, header); err != nil {
+				return err
+			}
+		case tar.TypeLink:
+			if err := extractHardLink(root, path, header); err != nil {
+				return err
+			}
+		default:
+			// sockets, devices and pipes can not be transferred
+			fmt.Fprintf(os.Stderr, "\nSkipping %s, only files, directories and links can be pulled\n", header.Name)
+		}
+	}
+}
+
+// extractSymlink creates the symlink, targets outside of the root are rejected
+func extractSymlink(root string, path string, header *tar.Header) error {
+	target := filepath.FromSlash(header.Linkname)
+	if !filepath.IsAbs(target) {
+		directory, err := filepath.EvalSymlinks(filepath.Dir(path))
+		if err != nil {
+			return err
+		}
+		target = filepath.Join(directory, target)
+	}
+
+	if inside, err := lexicallyInsideRoot(root, target); err != nil {
+		return err
+	} else if !inside {
+		return fmt.Errorf("Invalid link %s of %s in archive, the target leaves the destination", header.Linkname, header.Name)
+	}
+
+	if err := removeExisting(path); err != nil {
+		return err
+	}
+
+	return os.Symlink(header.Linkname, path)
+}
+
+// extractHardLink links the path to a previously extracted file of the archive
+func extractHardLink(root string, path string, header *tar.Header) error {
+	name, err := archivePath(header.Linkname)
+	if err != nil {
+		return err
+	}
+
+	target := filepath.Join(root, name)
+
+	info, err := os.Lstat(target)
+	if err != nil {
+		return err
+	}
+
+	if !info.Mode().IsRegular() {
+		return fmt.Errorf("Invalid link %s of %s in archive, the target is not a file", header.Linkname, header.Name)
+	}
+
+	if inside, err := insideRoot(root, filepath.Dir(target)); err != nil {
+		return err
+	} else if !inside {
+		return fmt.Errorf("Invalid link %s of %s in archive, the target leaves the destination", header.Linkname, header.Name)
+	}
+
+	if err := removeExisting(path); err != nil {
+		return err
+	}
+
+	return os.Link(target, path)
+}
+
+func extractFile(reader io.Reader, path string, mode os.FileMode, progress io.Writer) error {
+	file, err := os.OpenFile(path, os.O_CREATE|os.O_WRONLY|os.O_TRUNC, mode)
+	if err != nil {
+		return err
+	}
+
+	if _, err := io.Copy(io.MultiWriter(file, progress), reader); err != nil {
+		file.Close()
+		return err
+	}
+
+	return file.Close()
+}
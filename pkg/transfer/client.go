@@ -0,0 +1,181 @@
+package transfer
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"os"
+	"strconv"
+	"strings"
+
+	"github.com/salberternst/workspace/pkg/k8s"
+)
+
+// partialSuffix marks files which are still being transferred, an interrupted
+// transfer continues from the size of the partial file
+const partialSuffix = ".workspace-partial"
+
+const (
+	kindFile      = "file"
+	kindDirectory = "directory"
+)
+
+// Options configure a transfer
+type Options struct {
+	// Compress compresses the data with gzip while it is transferred
+	Compress bool
+	// ResumeThreshold is the size above which files are transferred one by one
+	// so that interrupted transfers can be resumed
+	ResumeThreshold int64
+}
+
+// Client transfers files from and to a container. Relative remote paths are
+// relative to the home folder of the container user.
+type Client struct {
+	namespace string
+	pod       string
+	container string
+}
+
+func NewClient(namespace string, pod string, container string) *Client {
+	return &Client{
+		namespace: namespace,
+		pod:       pod,
+		container: container,
+	}
+}
+
+func (o *Client) run(script string, stdin io.Reader, stdout io.Writer, args ...string) error {
+	var stderr bytes.Buffer
+
+	command := append([]string{"bash", "-c", "cd || exit 1\n" + script, "workspace-transfer"}, args...)
+
+	err := k8s.ExecuteInPod(o.namespace, o.pod, o.container, command, k8s.ExecStreams{
+		Stdin:  stdin,
+		Stdout: stdout,
+		Stderr: &stderr,
+	})
+
+	if err != nil {
+		if message := strings.TrimSpace(stderr.String()); message != "" {
+			return fmt.Errorf("%s: %w", message, err)
+		}
+		return err
+	}
+
+	return nil
+}
+
+func (o *Client) output(script string, args ...string) (string, error) {
+	var stdout bytes.Buffer
+	err := o.run(script, nil, &stdout, args...)
+	return strings.TrimSpace(stdout.String()), err
+}
+
+// remoteKind returns whether the path is a file or a directory or an empty
+// string if it does not exist
+func (o *Client) remoteKind(path string) (string, error) {
+	return o.output(`if [ -d "$1" ]; then echo directory; elif [ -f "$1" ]; then echo file; fi`, path)
+}
+
+// remoteStat returns the size and permissions of a file, -1 if it does not exist
+func (o *Client) remoteStat(path string) (int64, os.FileMode, error) {
+	output, err := o.output(`if [ -f "$1" ]; then stat -c '%s %a' -- "$1"; fi`, path)
+	if err != nil || output == "" {
+		return -1, 0, err
+	}
+
+	fields := strings.Fields(output)
+	if len(fields) != 2 {
+		return -1, 0, fmt.Errorf("unexpected output of stat: %s", output)
+	}
+
+	size, err := strconv.ParseInt(fields[0], 10, 64)
+	if err != nil {
+		return -1, 0, err
+	}
+
+	mode, err := strconv.ParseUint(fields[1], 8, 32)
+	if err != nil {
+		return -1, 0, err
+	}
+
+	return size, os.FileMode(mode), nil
+}
+
+// remoteHash hashes the first length bytes of the file
+func (o *Client) remoteHash(path string, length int64) (string, error) {
+	output, err := o.output(`head -c "$2" -- "$1" | sha256sum`, path, strconv.FormatInt(length, 10))
+	if err != nil {
+		return "", err
+	}
+
+	fields := strings.Fields(output)
+	if len(fields) == 0 {
+		return "", fmt.Errorf("unexpected output of sha256sum: %s", output)
+	}
+
+	return fields[0], nil
+}
+
+// remoteDirectorySize sums the size of all files in the directory
+func (o *Client) remoteDirectorySize(path string) (int64, error) {
+	output, err := o.output(`cd -- "$1" && find . -type f -exec stat -c %s {} + | awk '{ size += $1 } END { print size + 0 }'`, path)
+	if err != nil {
+		return 0, err
+	}
+
+	return strconv.ParseInt(output, 10, 64)
+}
+
+// remoteLargeFiles lists the files in the directory larger than the threshold
+func (o *Client) remoteLargeFiles(path string, threshold int64) ([]string, error) {
+	output, err := o.output(`cd -- "$1" && find . -type f -size +"$2"c`, path, strconv.FormatInt(threshold, 10))
+	if err != nil || output == "" {
+		return nil, err
+	}
+
+	var files []string
+	for _, file := range strings.Split(output, "\n") {
+		files = append(files, strings.TrimPrefix(file, "./"))
+	}
+
+	return files, nil
+}
+
+// localHash hashes the first length bytes of the file
+func localHash(path string, length int64) (string, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return "", err
+	}
+	defer file.Close()
+
+	hash := sha256.New()
+	if _, err := io.CopyN(hash, file, length); err != nil {
+		return "", err
+	}
+
+	return hex.EncodeToString(hash.Sum(nil)), nil
+}
+
+// canResume checks if the first partialSize bytes of the local and remote file match
+func (o *Client) canResume(localPath string, remotePath string, partialSize int64, size int64) (bool, error) {
+	if partialSize <= 0 || partialSize > size {
+		return false, nil
+	}
+
+	local, err := localHash(localPath, partialSize)
+	if err != nil {
+		return false, err
+	}
+
+	remote, err := o.remoteHash(remotePath, partialSize)
+	if err != nil {
+		return false, err
+	}
+
+	return local == remote, nil
+}
@@ -0,0 +1,186 @@
+package transfer
+
+import (
+	"compress/gzip"
+	"fmt"
+	"io"
+	"os"
+	"path"
+	"path/filepath"
+	"strconv"
+	"strings"
+
+	"github.com/schollz/progressbar/v3"
+)
+
+// pullFileScript writes the file starting at the offset to stdout, pipefail
+// reports a failing tail if the output is compressed
+const pullFileScript = `set -o pipefail
+tail -c +"$(( $2 + 1 ))" -- "$1"%s`
+
+// pullDirectoryScript writes the directory without the files larger than the
+// threshold as tar. The entries are passed to tar as null separated list
+// instead of excluding the large files, exclude patterns would be matched as
+// wildcards.
+const pullDirectoryScript = `set -o pipefail
+cd -- "$1" || exit 1
+find . \( -type f -size +"$2"c \) -o -print0 | tar -c %s --null --no-recursion -T - -f -`
+
+func compressPipe(compress bool) string {
+	if compress {
+		return " | gzip -c"
+	}
+	return ""
+}
+
+// Pull copies a file or directory from the container. If the local path is an
+// existing directory the remote path is copied into it.
+func (o *Client) Pull(remotePath string, localPath string, options Options) error {
+	kind, err := o.remoteKind(remotePath)
+	if err != nil {
+		return err
+	}
+
+	if kind == "" {
+		return fmt.Errorf("%s does not exist in the workspace", remotePath)
+	}
+
+	if info, err := os.Stat(localPath); (err == nil && info.IsDir()) || strings.HasSuffix(localPath, string(filepath.Separator)) {
+		localPath = filepath.Join(localPath, path.Base(remotePath))
+	}
+
+	if kind == kindFile {
+		size, mode, err := o.remoteStat(remotePath)
+		if err != nil {
+			return err
+		}
+
+		progressBar := progressbar.DefaultBytes(size, "pulling")
+		defer progressBar.Finish()
+
+		return o.pullFile(remotePath, localPath, size, mode, options, progressBar)
+	}
+
+	total, err := o.remoteDirectorySize(remotePath)
+	if err != nil {
+		return err
+	}
+
+	largeFiles, err := o.remoteLargeFiles(remotePath, options.ResumeThreshold)
+	if err != nil {
+		return err
+	}
+
+	progressBar := progressbar.DefaultBytes(total, "pulling")
+	defer progressBar.Finish()
+
+	if err := o.pullDirectory(remotePath, localPath, options, progressBar); err != nil {
+		return err
+	}
+
+	for _, name := range largeFiles {
+		remoteFile := path.Join(remotePath, name)
+
+		size, mode, err := o.remoteStat(remoteFile)
+		if err != nil {
+			return err
+		}
+
+		if err := o.pullFile(remoteFile, filepath.Join(localPath, filepath.FromSlash(name)), size, mode, options, progressBar); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// stream runs the script and returns its output, decompressed if needed
+func (o *Client) stream(script string, compress bool, args ...string) (io.ReadCloser, error) {
+	pipeReader, pipeWriter := io.Pipe()
+
+	go func() {
+		pipeWriter.CloseWithError(o.run(script, nil, pipeWriter, args...))
+	}()
+
+	if !compress {
+		return pipeReader, nil
+	}
+
+	reader, err := gzip.NewReader(pipeReader)
+	if err != nil {
+		pipeReader.Close()
+		return nil, err
+	}
+
+	return struct {
+		io.Reader
+		io.Closer
+	}{reader, pipeReader}, nil
+}
+
+// pullDirectory streams the directory without the large files as tar
+func (o *Client) pullDirectory(remotePath string, localPath string, options Options, progressBar *progressbar.ProgressBar) error {
+	if err := os.MkdirAll(localPath, 0755); err != nil {
+		return err
+	}
+
+	script := fmt.Sprintf(pullDirectoryScript, tarCompressionFlag(options.Compress))
+
+	reader, err := o.stream(script, options.Compress, remotePath, strconv.FormatInt(options.ResumeThreshold, 10))
+	if err != nil {
+		return err
+	}
+	defer reader.Close()
+
+	return extractTar(reader, localPath, progressBar)
+}
+
+// pullFile transfers a single file and continues a previously interrupted transfer
+func (o *Client) pullFile(remotePath string, localPath string, size int64, mode os.FileMode, options Options, progressBar *progressbar.ProgressBar) error {
+	partialPath := localPath + partialSuffix
+
+	var partialSize int64 = -1
+	if info, err := os.Stat(partialPath); err == nil {
+		partialSize = info.Size()
+	}
+
+	resume, err := o.canResume(partialPath, remotePath, partialSize, size)
+	if err != nil {
+		return err
+	}
+
+	flags := os.O_CREATE | os.O_WRONLY | os.O_TRUNC
+
+	var offset int64
+	if resume {
+		offset = partialSize
+		flags = os.O_WRONLY | os.O_APPEND
+		progressBar.Add64(offset)
+	}
+
+	if err := os.MkdirAll(filepath.Dir(localPath), 0755); err != nil {
+		return err
+	}
+
+	file, err := os.OpenFile(partialPath, flags, mode)
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+
+	reader, err := o.stream(fmt.Sprintf(pullFileScript, compressPipe(options.Compress)), options.Compress, remotePath, strconv.FormatInt(offset, 10))
+	if err != nil {
+		return err
+	}
+	defer reader.Close()
+
+	if _, err := io.Copy(io.MultiWriter(file, progressBar), reader); err != nil {
+		return err
+	}
+
+	if err := file.Close(); err != nil {
+		return err
+	}
+
+	return os.Rename(partialPath, localPath)
+}
@@ -0,0 +1,145 @@
+package transfer
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"path"
+	"path/filepath"
+	"strconv"
+	"strings"
+
+	"github.com/schollz/progressbar/v3"
+)
+
+// pushFileScript appends stdin to the partial file and moves it into place
+const pushFileScript = `mkdir -p -- "$(dirname -- "$1")" || exit 1
+if [ "$3" = 0 ]; then : > "$2" || exit 1; fi
+%s >> "$2" && chmod "$4" -- "$2" && mv -f -- "$2" "$1"`
+
+const pushDirectoryScript = `mkdir -p -- "$1" && tar -x %s -f - -C "$1"`
+
+func decompressCommand(compress bool) string {
+	if compress {
+		return "gzip -dc"
+	}
+	return "cat"
+}
+
+func tarCompressionFlag(compress bool) string {
+	if compress {
+		return "-z"
+	}
+	return ""
+}
+
+// Push copies a local file or directory to the container. If the remote path is
+// an existing directory the local path is copied into it.
+func (o *Client) Push(localPath string, remotePath string, options Options) error {
+	info, err := os.Stat(localPath)
+	if err != nil {
+		return err
+	}
+
+	kind, err := o.remoteKind(remotePath)
+	if err != nil {
+		return err
+	}
+
+	if kind == kindDirectory || strings.HasSuffix(remotePath, "/") {
+		remotePath = path.Join(remotePath, filepath.Base(localPath))
+	}
+
+	if !info.IsDir() {
+		progressBar := progressbar.DefaultBytes(info.Size(), "pushing")
+		defer progressBar.Finish()
+
+		return o.pushFile(localPath, remotePath, info.Size(), info.Mode(), options, progressBar)
+	}
+
+	total, largeFiles, err := localFiles(localPath, options.ResumeThreshold)
+	if err != nil {
+		return err
+	}
+
+	progressBar := progressbar.DefaultBytes(total, "pushing")
+	defer progressBar.Finish()
+
+	if err := o.pushDirectory(localPath, remotePath, largeFiles, options, progressBar); err != nil {
+		return err
+	}
+
+	for name, size := range largeFiles {
+		localFile := filepath.Join(localPath, filepath.FromSlash(name))
+
+		fileInfo, err := os.Stat(localFile)
+		if err != nil {
+			return err
+		}
+
+		if err := o.pushFile(localFile, path.Join(remotePath, name), size, fileInfo.Mode(), options, progressBar); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// pushDirectory streams the directory without the large files as tar
+func (o *Client) pushDirectory(localPath string, remotePath string, largeFiles map[string]int64, options Options, progressBar *progressbar.ProgressBar) error {
+	pipeReader, pipeWriter := io.Pipe()
+	defer pipeReader.Close()
+
+	go func() {
+		pipeWriter.CloseWithError(writeTar(pipeWriter, localPath, largeFiles, progressBar))
+	}()
+
+	var stdin io.ReadCloser = pipeReader
+	if options.Compress {
+		stdin = compressReader(pipeReader)
+		defer stdin.Close()
+	}
+
+	return o.run(fmt.Sprintf(pushDirectoryScript, tarCompressionFlag(options.Compress)), stdin, nil, remotePath)
+}
+
+// pushFile transfers a single file and continues a previously interrupted transfer
+func (o *Client) pushFile(localPath string, remotePath string, size int64, mode os.FileMode, options Options, progressBar *progressbar.ProgressBar) error {
+	partialPath := remotePath + partialSuffix
+
+	partialSize, _, err := o.remoteStat(partialPath)
+	if err != nil {
+		return err
+	}
+
+	resume, err := o.canResume(localPath, partialPath, partialSize, size)
+	if err != nil {
+		return err
+	}
+
+	var offset int64
+	if resume {
+		offset = partialSize
+		progressBar.Add64(offset)
+	}
+
+	file, err := os.Open(localPath)
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+
+	if _, err := file.Seek(offset, io.SeekStart); err != nil {
+		return err
+	}
+
+	var stdin io.ReadCloser = io.NopCloser(io.TeeReader(file, progressBar))
+	if options.Compress {
+		stdin = compressReader(stdin)
+		defer stdin.Close()
+	}
+
+	script := fmt.Sprintf(pushFileScript, decompressCommand(options.Compress))
+
+	return o.run(script, stdin, nil, remotePath, partialPath, strconv.FormatInt(offset, 10), strconv.FormatUint(uint64(mode.Perm()), 8))
+}
@@ -8,19 +8,25 @@ import (
 const PrivateKeyFileName = "id_devspace_ecdsa"
 
 func EnsureConfigFolder(name string, namespace string) error {
+	_, err := GetConfigFolder(name, namespace)
+	return err
+}
+
+// GetConfigFolder returns the config folder of the workspace and creates it if needed
+func GetConfigFolder(name string, namespace string) (string, error) {
 	homedir, err := os.UserHomeDir()
 	if err != nil {
-		return err
+		return "", err
 	}
 
 	configPath := filepath.Join(homedir, ".workspace", namespace, name)
 
 	err = os.MkdirAll(configPath, os.ModePerm)
 	if err != nil {
-		return err
+		return "", err
 	}
 
-	return nil
+	return configPath, nil
 }
 
 func WritePrivateKey(name string, namespace string, privateKey []byte) (string, error) {
@@ -0,0 +1,66 @@
+package utils
+
+import (
+	"bytes"
+	"io"
+	"sync"
+)
+
+// PrefixWriter prefixes every line written to the underlying writer. Complete
+// lines are written at once while holding the mutex, so several writers can
+// share the same destination without interleaving lines.
+type PrefixWriter struct {
+	mutex  *sync.Mutex
+	writer io.Writer
+	prefix []byte
+	buffer []byte
+}
+
+func NewPrefixWriter(writer io.Writer, mutex *sync.Mutex, prefix string) *PrefixWriter {
+	return &PrefixWriter{
+		mutex:  mutex,
+		writer: writer,
+		prefix: []byte(prefix),
+	}
+}
+
+func (o *PrefixWriter) Write(p []byte) (int, error) {
+	o.buffer = append(o.buffer, p...)
+
+	for {
+		index := bytes.IndexByte(o.buffer, '\n')
+		if index < 0 {
+			return len(p), nil
+		}
+
+		if err := o.writeLine(o.buffer[:index+1]); err != nil {
+			return 0, err
+		}
+
+		o.buffer = o.buffer[index+1:]
+	}
+}
+
+// Flush writes a remaining incomplete line
+func (o *PrefixWriter) Flush() error {
+	if len(o.buffer) == 0 {
+		return nil
+	}
+
+	line := append(o.buffer, '\n')
+	o.buffer = nil
+
+	return o.writeLine(line)
+}
+
+func (o *PrefixWriter) writeLine(line []byte) error {
+	o.mutex.Lock()
+	defer o.mutex.Unlock()
+
+	if _, err := o.writer.Write(o.prefix); err != nil {
+		return err
+	}
+
+	_, err := o.writer.Write(line)
+	return err
+}
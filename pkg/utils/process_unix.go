@@ -0,0 +1,17 @@
+//go:build !windows
+
+package utils
+
+import (
+	"os/exec"
+	"syscall"
+)
+
+// StartDetached starts the command in a new session so that it keeps running
+// after the current process and its terminal exit
+func StartDetached(cmd *exec.Cmd) error {
+	cmd.SysProcAttr = &syscall.SysProcAttr{
+		Setsid: true,
+	}
+	return cmd.Start()
+}
@@ -0,0 +1,22 @@
+//go:build windows
+
+package utils
+
+import (
+	"os/exec"
+	"syscall"
+)
+
+const (
+	createNewProcessGroup = 0x00000200
+	detachedProcess       = 0x00000008
+)
+
+// StartDetached starts the command without a console so that it keeps running
+// after the current process and its terminal exit
+func StartDetached(cmd *exec.Cmd) error {
+	cmd.SysProcAttr = &syscall.SysProcAttr{
+		CreationFlags: createNewProcessGroup | detachedProcess,
+	}
+	return cmd.Start()
+}
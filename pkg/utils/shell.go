@@ -0,0 +1,38 @@
+package utils
+
+import (
+	"strings"
+)
+
+// QuoteArgument quotes a single argument for a posix shell
+func QuoteArgument(argument string) string {
+	if argument == "" {
+		return "''"
+	}
+
+	if strings.IndexFunc(argument, isUnsafeShellRune) < 0 {
+		return argument
+	}
+
+	return "'" + strings.ReplaceAll(argument, "'", `'"'"'`) + "'"
+}
+
+// QuoteCommand joins the arguments into a single command line which is split
+// back into the exact same arguments by a posix shell
+func QuoteCommand(arguments []string) string {
+	quoted := make([]string, len(arguments))
+	for index, argument := range arguments {
+		quoted[index] = QuoteArgument(argument)
+	}
+	return strings.Join(quoted, " ")
+}
+
+func isUnsafeShellRune(r rune) bool {
+	switch {
+	case r >= 'a' && r <= 'z', r >= 'A' && r <= 'Z', r >= '0' && r <= '9':
+		return false
+	case strings.ContainsRune("-_./:=@%+,", r):
+		return false
+	}
+	return true
+}
@@ -2,9 +2,8 @@ package utils
 
 import (
 	"context"
-	"fmt"
 	"os"
-	"time"
+	"sync"
 
 	"golang.org/x/term"
 	"k8s.io/client-go/tools/remotecommand"
@@ -24,7 +23,10 @@ type Terminal struct {
 	oldState  *term.State
 	fd        int
 	SizeQueue termSizeQueue
+	lastSize  remotecommand.TerminalSize
 	cancel    context.CancelFunc
+	done      chan struct{}
+	closeOnce sync.Once
 }
 
 func NewTerminal() (*Terminal, error) {
@@ -43,36 +45,71 @@ func NewTerminal() (*Terminal, error) {
 	return &terminal, nil
 }
 
+// MonitorSize sends the current size to the SizeQueue and afterwards every
+// changed size whenever the terminal is resized
 func (o *Terminal) MonitorSize() {
 	ctx, cancel := context.WithCancel(context.Background())
 
 	o.cancel = cancel
+	o.done = make(chan struct{})
+
+	resize, stop := notifyResize()
 
 	go func() {
-		for {
-			termWidth, termHeight, err := term.GetSize(o.fd)
-			if err != nil {
-				fmt.Printf("Error: %s", err.Error())
-			}
+		defer close(o.done)
+		defer stop()
 
-			termSize := remotecommand.TerminalSize{Width: uint16(termWidth), Height: uint16(termHeight)}
-			o.SizeQueue <- termSize
+		o.pushSize()
 
+		for {
 			select {
 			case <-ctx.Done():
 				return
-			default:
-				time.Sleep(2 * time.Second)
+			case <-resize:
+				o.pushSize()
 			}
 		}
 	}()
 }
 
-func (o *Terminal) Close() error {
-	if o.cancel != nil {
-		o.cancel()
+func (o *Terminal) pushSize() {
+	width, height, err := term.GetSize(o.fd)
+	if err != nil {
+		return
+	}
+
+	size := remotecommand.TerminalSize{Width: uint16(width), Height: uint16(height)}
+	if size == o.lastSize {
+		return
 	}
 
+	o.lastSize = size
+
+	// replace a size which was not consumed yet instead of blocking, only the
+	// latest size is of interest
+	for {
+		select {
+		case o.SizeQueue <- size:
+			return
+		default:
+			select {
+			case <-o.SizeQueue:
+			default:
+			}
+		}
+	}
+}
+
+func (o *Terminal) Close() error {
+	o.closeOnce.Do(func() {
+		if o.cancel != nil {
+			o.cancel()
+			<-o.done
+		}
+
+		close(o.SizeQueue)
+	})
+
 	err := term.Restore(o.fd, o.oldState)
 	if err != nil {
 		return err
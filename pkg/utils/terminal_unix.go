@@ -0,0 +1,37 @@
+//go:build !windows
+
+package utils
+
+import (
+	"os"
+	"os/signal"
+	"syscall"
+)
+
+// notifyResize delivers an event on every SIGWINCH until stop is called
+func notifyResize() (<-chan struct{}, func()) {
+	signals := make(chan os.Signal, 1)
+	resize := make(chan struct{}, 1)
+	done := make(chan struct{})
+
+	signal.Notify(signals, syscall.SIGWINCH)
+
+	go func() {
+		for {
+			select {
+			case <-signals:
+				select {
+				case resize <- struct{}{}:
+				default:
+				}
+			case <-done:
+				return
+			}
+		}
+	}()
+
+	return resize, func() {
+		signal.Stop(signals)
+		close(done)
+	}
+}
@@ -0,0 +1,37 @@
+//go:build windows
+
+package utils
+
+import (
+	"time"
+)
+
+// resizePollInterval is used as windows consoles do not signal size changes
+const resizePollInterval = 250 * time.Millisecond
+
+// notifyResize delivers an event on every poll interval until stop is called,
+// sizes which did not change are dropped by pushSize
+func notifyResize() (<-chan struct{}, func()) {
+	resize := make(chan struct{}, 1)
+	ticker := time.NewTicker(resizePollInterval)
+	done := make(chan struct{})
+
+	go func() {
+		for {
+			select {
+			case <-ticker.C:
+				select {
+				case resize <- struct{}{}:
+				default:
+				}
+			case <-done:
+				return
+			}
+		}
+	}()
+
+	return resize, func() {
+		ticker.Stop()
+		close(done)
+	}
+}